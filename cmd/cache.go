@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/kataras/iris-cli/project"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+// iris-cli cache list
+// iris-cli cache prune --older-than=720h
+func cacheCommand() *cobra.Command {
+	var (
+		cacheDir = project.DefaultCacheDir()
+	)
+
+	cmd := &cobra.Command{
+		Use:           "cache",
+		Short:         "Manage the local cache of the downloaded projects and registries",
+		SilenceErrors: true,
+	}
+
+	cmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", cacheDir, "--cache-dir=DIR to use a different cache directory, defaults to the "+project.CacheDirEnv+" environment variable or the user cache directory")
+
+	cmd.AddCommand(cacheListCommand(&cacheDir))
+	cmd.AddCommand(cachePruneCommand(&cacheDir))
+
+	return cmd
+}
+
+func cacheListCommand(cacheDir *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "list",
+		Short:         "List the cached projects and registries",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := project.NewCache(*cacheDir).List()
+			if err != nil {
+				return err
+			}
+
+			if len(entries) == 0 {
+				cmd.Println("cache is empty")
+				return nil
+			}
+
+			var total int64
+			for _, entry := range entries {
+				total += entry.Size
+
+				name := entry.Repo
+				if entry.Version != "" {
+					name += "@" + entry.Version
+				}
+
+				commit := entry.Commit
+				if len(commit) > 7 {
+					commit = commit[:7]
+				}
+
+				cmd.Printf("• [%s] %s %s %s (%s)\n", entry.Kind, name, commit, humanize.Bytes(uint64(entry.Size)), humanize.Time(entry.Time))
+			}
+
+			cmd.Printf("%d entries, %s total at <%s>\n", len(entries), humanize.Bytes(uint64(total)), *cacheDir)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func cachePruneCommand(cacheDir *string) *cobra.Command {
+	var (
+		olderThan time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:           "prune",
+		Short:         "Remove all or the old cache entries",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			removed, err := project.NewCache(*cacheDir).Prune(olderThan)
+			if err != nil {
+				return err
+			}
+
+			var freed int64
+			for _, entry := range removed {
+				freed += entry.Size
+			}
+
+			cmd.Printf("%d entries removed, %s freed\n", len(removed), humanize.Bytes(uint64(freed)))
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&olderThan, "older-than", olderThan, "--older-than=720h to remove only the entries cached before that duration, defaults to all")
+
+	return cmd
+}
@@ -9,7 +9,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var timeFormat string
+var (
+	timeFormat string
+	// offline reports whether the new and run commands should install projects from the local cache only.
+	offline bool
+)
 
 // New returns the root command.
 func New(buildRevision, buildTime string) *cobra.Command {
@@ -53,18 +57,25 @@ Complete documentation is available at https://github.com/kataras/iris-cli`,
 	// Shared flags.
 	rootCmd.PersistentFlags().BoolVarP(&verboseMode, "verbose", "v", verboseMode, "-v to enable verbose messages")
 	rootCmd.PersistentFlags().StringVar(&proxyAddr, "proxy", proxyAddr, "--proxy=env to load from system or ip:port form, e.g. 51.158.178.4:3128")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", offline, "--offline to install projects from the local cache only")
 	rootCmd.PersistentFlags().StringVar(&timeFormat, "time-format", timeFormat,
 		`--time-format="Mon, 02 Jan 2006 15:04:05 GMT" or "http" to customize the log time format, defaults to empty, no time info`)
 
 	// Commands.
 	rootCmd.AddCommand(initCommand())
 	rootCmd.AddCommand(newCommand())
+	rootCmd.AddCommand(upgradeCommand())
 	rootCmd.AddCommand(runCommand())
 	rootCmd.AddCommand(cleanCommand())
 	rootCmd.AddCommand(unistallCommand())
 	rootCmd.AddCommand(addCommand())
 	rootCmd.AddCommand(checkCommand())
 	rootCmd.AddCommand(statsCommand())
+	rootCmd.AddCommand(cacheCommand())
+	rootCmd.AddCommand(registryCommand())
+	rootCmd.AddCommand(mirrorCommand())
+	rootCmd.AddCommand(configCommand())
+	rootCmd.AddCommand(signCommand())
 
 	return rootCmd
 }
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kataras/iris-cli/project"
+
+	"github.com/spf13/cobra"
+)
+
+// iris-cli config convert --to=toml
+// iris-cli config convert --to=json --dir=./myproject
+// iris-cli config schema > iris.schema.json
+// iris-cli config schema --format=toml
+// iris-cli config get livereload.port
+// iris-cli config set livereload.port 35730
+// iris-cli config set watcher.ignore_dirs --append dist
+// iris-cli config set watcher.backend --remove .proto
+// iris-cli config unset livereload.port
+func configCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "config",
+		Short:         "Manage the project configuration file",
+		SilenceErrors: true,
+	}
+
+	cmd.AddCommand(configConvertCommand())
+	cmd.AddCommand(configSchemaCommand())
+	cmd.AddCommand(configGetCommand())
+	cmd.AddCommand(configSetCommand())
+	cmd.AddCommand(configUnsetCommand())
+
+	return cmd
+}
+
+func configConvertCommand() *cobra.Command {
+	var (
+		dir = "."
+		to  string
+	)
+
+	cmd := &cobra.Command{
+		Use:           "convert",
+		Short:         "Convert the project configuration file to another format",
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := loadProject([]string{dir})
+			if err != nil {
+				return err
+			}
+
+			oldName := p.ConfigFile()
+			name, err := p.Convert(to)
+			if err != nil {
+				return err
+			}
+
+			if name == oldName {
+				cmd.Printf("Project file is already <%s>\n", name)
+				return nil
+			}
+
+			cmd.Printf("Project file <%s> converted to <%s>\n", oldName, name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", dir, "--dir=./myproject the project directory")
+	cmd.Flags().StringVar(&to, "to", "", "--to="+strings.Join(project.ProjectFormats, "|")+" the format of the project file")
+	cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func configSchemaCommand() *cobra.Command {
+	var (
+		format = "yml"
+	)
+
+	cmd := &cobra.Command{
+		Use:           "schema",
+		Short:         "Print the JSON Schema of the project configuration file",
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schema, err := project.Schema(format)
+			if err != nil {
+				return err
+			}
+
+			cmd.Println(string(schema))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", format, "--format="+strings.Join(project.ProjectFormats, "|")+" the format of the project file, its field names differ")
+
+	return cmd
+}
+
+func configGetCommand() *cobra.Command {
+	var (
+		dir = "."
+	)
+
+	cmd := &cobra.Command{
+		Use:           "get <field>",
+		Short:         "Print a field of the project configuration file, e.g. livereload.port",
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, err := loadProject([]string{dir})
+			if err != nil {
+				return err
+			}
+
+			v, err := p.GetConfig(args[0])
+			if err != nil {
+				return err
+			}
+
+			switch v.(type) {
+			case string, bool, int, int64, float64:
+				cmd.Println(v)
+			default:
+				b, err := json.MarshalIndent(v, "", "  ")
+				if err != nil {
+					return err
+				}
+
+				cmd.Println(string(b))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", dir, "--dir=./myproject the project directory")
+
+	return cmd
+}
+
+func configSetCommand() *cobra.Command {
+	var (
+		dir                        = "."
+		appendValues, removeValues bool
+	)
+
+	cmd := &cobra.Command{
+		Use:           "set <field> <value>...",
+		Short:         "Set a field of the project configuration file, e.g. livereload.port 35730",
+		SilenceErrors: true,
+		Args:          cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			op := project.ConfigSet
+			switch {
+			case appendValues && removeValues:
+				return fmt.Errorf("--append and --remove cannot be used together")
+			case appendValues:
+				op = project.ConfigAppend
+			case removeValues:
+				op = project.ConfigRemove
+			}
+
+			return editConfig(cmd, dir, args[0], op, args[1:]...)
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", dir, "--dir=./myproject the project directory")
+	cmd.Flags().BoolVar(&appendValues, "append", appendValues, "--append to append the values to a list field")
+	cmd.Flags().BoolVar(&removeValues, "remove", removeValues, "--remove to remove the values from a list field")
+
+	return cmd
+}
+
+func configUnsetCommand() *cobra.Command {
+	var (
+		dir = "."
+	)
+
+	cmd := &cobra.Command{
+		Use:           "unset <field>",
+		Short:         "Remove a field from the project configuration file, so its default value is used",
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return editConfig(cmd, dir, args[0], project.ConfigUnset)
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", dir, "--dir=./myproject the project directory")
+
+	return cmd
+}
+
+// editConfig edits the "field" of the project file of the "dir" directory and prints its new value.
+func editConfig(cmd *cobra.Command, dir, field string, op project.ConfigOp, values ...string) error {
+	p, err := loadProject([]string{dir})
+	if err != nil {
+		return err
+	}
+
+	if err = p.EditConfig(field, op, values...); err != nil {
+		return err
+	}
+
+	if op == project.ConfigUnset {
+		cmd.Printf("Field <%s> removed from <%s>\n", field, p.ConfigFile())
+		return nil
+	}
+
+	v, err := p.GetConfig(field)
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("Field <%s> of <%s> set to: %v\n", field, p.ConfigFile(), v)
+	return nil
+}
+
+// loadProject loads the project of the first argument or the current directory.
+func loadProject(args []string) (*project.Project, error) {
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	return project.LoadFromDisk(path)
+}
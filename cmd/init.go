@@ -71,6 +71,10 @@ func initCommand() *cobra.Command {
 						return err
 					}
 
+					if skip, err := skipProjectFile(rel); skip {
+						return err
+					}
+
 					files = append(files, filepath.ToSlash(rel))
 					return nil
 				})
@@ -192,6 +196,10 @@ func findFiles(projectPath string) ([]string, error) {
 			return filepath.SkipDir
 		}
 
+		if skip, err := skipProjectFile(rel); skip {
+			return err
+		}
+
 		rel = filepath.ToSlash(rel)
 
 		isDir := info.IsDir()
@@ -210,19 +218,28 @@ func findFiles(projectPath string) ([]string, error) {
 		return nil, err
 	}
 
-	if m := ignore.Relative(project.ProjectFilename, false); m == nil { // if entry does not exist at all. Skip if included by user.
-		// then add it to the gitignore.
-		f, err := os.OpenFile(gitIgnoreFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-		if err == nil {
-			_, err = f.WriteString("\n# ignore iris project file\n.iris.yml\n")
-			f.Close()
-			if err != nil {
-				return nil, err
-			}
+	return files, nil
+}
+
+// skipProjectFile reports whether the "rel" path is one of the iris project files,
+// the local state directory is git-ignored by itself.
+func skipProjectFile(rel string) (bool, error) {
+	rel = filepath.ToSlash(rel)
+	if rel == project.StateDir {
+		return true, filepath.SkipDir
+	}
+
+	if rel == project.LegacyProjectFilename {
+		return true, nil
+	}
+
+	for _, name := range project.ProjectFilenames {
+		if rel == name {
+			return true, nil
 		}
 	}
 
-	return files, nil
+	return false, nil
 }
 
 func findModulePath(projectPath string) string {
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/kataras/iris-cli/project"
+	"github.com/kataras/iris-cli/snippet"
+	"github.com/kataras/iris-cli/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// iris-cli mirror --out=./mirror
+// iris-cli mirror --out=./mirror --tag=mvc --version=basic@v1.0.0 --repo=iris-contrib/snippets
+func mirrorCommand() *cobra.Command {
+	var (
+		out         = "./mirror"
+		registries  []string
+		tag         string
+		versions    []string
+		repo        = defaultRepo
+		repoVersion = "main"
+		noSnippets  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:           "mirror",
+		Short:         "Mirror the registry, its projects and the snippets to a local directory",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectVersions := make(map[string]string, len(versions))
+			for _, v := range versions {
+				name, version := utils.SplitNameVersion(v)
+				if name == "" || version == "" {
+					return fmt.Errorf("version <%s>: expected a name@version value", v)
+				}
+				projectVersions[name] = version
+			}
+
+			out, err := filepath.Abs(out)
+			if err != nil {
+				return err
+			}
+
+			reg, err := openRegistry(cmd, registries)
+			if err != nil {
+				return err
+			}
+
+			names := reg.Filter(tag)
+			if len(names) == 0 {
+				return fmt.Errorf("no projects to mirror")
+			}
+
+			for name := range projectVersions {
+				if _, ok := reg.Projects[name]; !ok {
+					return fmt.Errorf("version <%s>: %w", name, project.ErrProjectNotExists)
+				}
+			}
+
+			cmd.Printf("Mirroring %d projects to <%s>\n", len(names), out)
+			mirror, issues, err := reg.Mirror(out, names, projectVersions)
+			if err != nil {
+				return err
+			}
+
+			registryFile := filepath.Join(out, project.MirrorRegistryFilename)
+			if err = mirror.SaveFile(registryFile); err != nil {
+				return err
+			}
+
+			for _, name := range mirror.Names {
+				cmd.Printf("• %s@%s\n", name, mirror.Projects[name].DefaultBranch)
+			}
+
+			snippetsDir := filepath.Join(out, "snippets")
+			if !noSnippets {
+				cmd.Printf("Mirroring snippets from <%s@%s>\n", repo, repoVersion)
+				files, err := snippet.Download(repo, repoVersion, snippetsDir)
+				if err != nil {
+					issues = append(issues, &project.RegistryIssue{Err: fmt.Errorf("snippets: %v", err)})
+				} else {
+					cmd.Printf("• %d snippets\n", len(files))
+				}
+			}
+
+			cmd.Println("Use the mirror with:")
+			cmd.Printf("  iris-cli new --registry=%s\n", registryFile)
+			cmd.Printf("  iris-cli run --registry=%s <project>\n", registryFile)
+			if !noSnippets {
+				cmd.Printf("  iris-cli add --repo=file://%s\n", filepath.ToSlash(snippetsDir))
+			}
+
+			if len(issues) > 0 {
+				for _, issue := range issues {
+					cmd.Printf("• %v\n", issue)
+				}
+				return fmt.Errorf("mirror <%s>: %d issues found", out, len(issues))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&out, "out", out, "--out=./mirror the directory to write the mirror")
+	cmd.Flags().StringArrayVar(&registries, "registry", nil, "--registry=URL or local file, or namespace=URL, repeat it to merge more registries, the first one has the highest priority")
+	cmd.Flags().StringVar(&tag, "tag", "", "--tag=mvc to mirror the projects with that tag only")
+	cmd.Flags().StringSliceVar(&versions, "version", nil, "--version=basic@v1.0.0 the version of a project to mirror, defaults to its default branch or main")
+	cmd.Flags().StringVar(&repo, "repo", repo, "--repo=iris-contrib/snippets the snippets repository")
+	cmd.Flags().StringVar(&repoVersion, "repo-version", repoVersion, "--repo-version=main the snippets repository version")
+	cmd.Flags().BoolVar(&noSnippets, "no-snippets", noSnippets, "--no-snippets to skip the snippets")
+
+	return cmd
+}
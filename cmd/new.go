@@ -1,16 +1,21 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
+	"strings"
 
 	"github.com/kataras/iris-cli/project"
 	"github.com/kataras/iris-cli/utils"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/cheggaaa/pb/v3"
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 // iris-cli new --registry=./_testfiles/registry.yml
@@ -36,6 +41,15 @@ func newCommand() *cobra.Command {
 				return b, err
 			},
 		}
+
+		registries     []string
+		maxSize        string
+		tag            string
+		onConflict     string
+		answersFile    string
+		acceptDefaults bool
+		dryRun         bool
+		answered       = make(map[string]struct{})
 	)
 
 	cmd := &cobra.Command{
@@ -43,25 +57,95 @@ func newCommand() *cobra.Command {
 		Short:         "New downloads and initializes a new starter kit project",
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cmd.Printf("Loading projects from <%s>\n", reg.Endpoint)
-			if err := reg.Load(); err != nil {
+			cache := project.NewCache("")
+			reg.Cache, reg.Offline = cache, offline
+			opts.Cache, opts.Offline = cache, offline
+
+			userConfig, err := project.LoadUserConfig("")
+			if err != nil {
 				return err
 			}
 
-			if len(args) == 0 {
-				err := survey.AskOne(&survey.Select{Message: "Choose a project to install:", Options: reg.Names, PageSize: 10}, &opts.Name)
-				if err != nil {
+			if reg.TrustedKeys, err = userConfig.PublicKeys(); err != nil {
+				return fmt.Errorf("user config: %v", err)
+			}
+
+			if err = loadRegistry(cmd, reg, registries, userConfig); err != nil {
+				return err
+			}
+
+			if answersFile != "" {
+				if err = loadNewAnswers(answersFile, &opts, answered); err != nil {
 					return err
 				}
+			}
+
+			for _, flagName := range []string{"module", "dest"} {
+				if cmd.Flags().Changed(flagName) {
+					answered[flagName] = struct{}{}
+				}
+			}
+
+			interactive := !acceptDefaults && isTerminal()
+			var missing []string
+
+			if len(args) > 0 {
+				opts.Name, opts.Version = utils.SplitNameVersion(args[0]) // split by @.
+			} else {
+				if _, ok := answered["project"]; !ok {
+					if !interactive {
+						return missingAnswersError([]string{"project"})
+					}
+
+					names := reg.Filter(tag)
+					if len(names) == 0 {
+						return fmt.Errorf("no projects tagged with <%s>", tag)
+					}
+
+					err := survey.AskOne(&survey.Select{
+						Message:  "Choose a project to install:",
+						Options:  names,
+						PageSize: 10,
+						Description: func(name string, _ int) string {
+							description := describeProject(reg.Projects[name])
+							if len(reg.Sources) > 1 {
+								description = strings.TrimSpace(description + " <" + reg.Projects[name].Source + ">")
+							}
+							return description
+						},
+						Filter: func(filter string, name string, _ int) bool {
+							p := reg.Projects[name]
+							filter = strings.ToLower(filter)
+							return strings.Contains(strings.ToLower(name), filter) ||
+								strings.Contains(strings.ToLower(p.Description), filter) || p.HasTag(filter)
+						},
+					}, &opts.Name)
+					if err != nil {
+						return err
+					}
+				}
 
 				repo, ok := reg.Exists(opts.Name)
 				if !ok {
 					return fmt.Errorf("project <%s> is not available", opts.Name)
 				}
 
-				availableVersions := utils.ListReleases(repo)
-				if len(availableVersions) > 1 {
-					availableVersions[0] = availableVersions[0] + " (latest)"
+				if defaultBranch := reg.Projects[opts.Name].DefaultBranch; defaultBranch != "" {
+					if _, ok := answered["version"]; !ok {
+						opts.Version = defaultBranch
+					}
+				}
+
+				var availableVersions []string
+				if _, ok := answered["version"]; !ok && interactive {
+					if offline {
+						availableVersions = cache.Versions(repo)
+					} else {
+						availableVersions = project.ListVersions(repo)
+					}
+					if len(availableVersions) > 1 {
+						availableVersions[0] = availableVersions[0] + " (latest)"
+					}
 				}
 
 				qs := []*survey.Question{
@@ -82,23 +166,106 @@ func newCommand() *cobra.Command {
 
 				if len(availableVersions) <= 1 {
 					// don't ask for version if only one or none exists.
-					qs = qs[1:]
+					answered["version"] = struct{}{}
 				}
 
-				if err := survey.Ask(qs, &opts); err != nil {
-					return err
+				var unanswered []*survey.Question
+				for _, q := range qs {
+					if _, ok := answered[q.Name]; !ok {
+						unanswered = append(unanswered, q)
+						missing = append(missing, q.Name)
+					}
 				}
 
-			} else {
-				opts.Name, opts.Version = utils.SplitNameVersion(args[0]) // split by @.
+				if interactive {
+					if err := survey.Ask(unanswered, &opts); err != nil {
+						return err
+					}
+				} else if !acceptDefaults && len(missing) > 0 {
+					return missingAnswersError(missing)
+				}
+			}
+
+			opts.Prompt = func(t *project.Template, answers map[string]interface{}) (map[string]interface{}, error) {
+				if interactive {
+					return askTemplate(t, answers)
+				}
+
+				var missing []string
+				for _, v := range t.Variables {
+					if _, ok := answers[v.Name]; ok {
+						continue
+					}
+
+					if acceptDefaults {
+						if _, err := v.Parse(v.DefaultValue()); err == nil {
+							continue
+						}
+					}
+
+					missing = append(missing, "variables."+v.Name)
+				}
+
+				if len(missing) > 0 {
+					return nil, missingAnswersError(missing)
+				}
+
+				return t.Answers(answers)
 			}
 
 			if !utils.Exists(opts.Dest) {
 				cmd.Printf("Directory <%s> will be created.\n", opts.Dest)
 			}
 
-			err := reg.Install(&opts)
+			strategies := []string{onConflict}
+			for _, strategy := range opts.Conflicts {
+				strategies = append(strategies, strategy)
+			}
+
+			for _, strategy := range strategies {
+				if strategy != "" && !project.IsConflictStrategy(strategy) {
+					return fmt.Errorf("unknown conflict strategy: %s, expected one of: %s", strategy, strings.Join(project.ConflictStrategies, ", "))
+				}
+			}
+
+			opts.OnConflict = onConflict
+			if onConflict == "" && interactive && !dryRun {
+				opts.ResolveConflict = askConflict(&opts.OnConflict)
+			}
+
+			if maxSize != "" {
+				n, err := humanize.ParseBytes(maxSize)
+				if err != nil {
+					return fmt.Errorf("max size: %v", err)
+				}
+				opts.MaxSize = int64(n)
+			}
+
+			if dryRun {
+				if err = reg.Resolve(&opts); err != nil {
+					return err
+				}
+
+				result, err := opts.DryRun()
+				if err != nil {
+					return err
+				}
+
+				printDryRun(cmd, result)
+				return nil
+			}
+
+			err = reg.Install(&opts)
 			if err != nil {
+				if extractErr, ok := project.IsExtractError(err); ok {
+					return fmt.Errorf("installation aborted, the archive of <%s> is unsafe: %s: %v", opts.Name, extractErr.Name, extractErr.Err)
+				}
+
+				var verifyErr *project.VerifyError
+				if errors.As(err, &verifyErr) {
+					return fmt.Errorf("installation aborted, module <%s> does not load: %v\nuse --no-verify to install it anyway", opts.Module, verifyErr)
+				}
+
 				return err
 			}
 
@@ -107,10 +274,23 @@ func newCommand() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&reg.Endpoint, "registry", reg.Endpoint, "--registry=URL or local file")
+	cmd.Flags().StringArrayVar(&registries, "registry", nil, "--registry=URL or local file, or namespace=URL, repeat it to merge more registries, the first one has the highest priority")
+	cmd.Flags().StringVar(&tag, "tag", "", "--tag=mvc to choose from the projects with that tag only")
 	cmd.Flags().StringVar(&opts.Dest, "dest", opts.Dest, "--dest=empty for current working directory or %GOPATH%/author")
 	cmd.Flags().StringVar(&opts.Module, "module", opts.Module, "--module=local module name")
 	cmd.Flags().StringToStringVar(&opts.Replacements, "replace", nil, "--replace=oldValue=newValue,oldValue2=newValue2")
+	cmd.Flags().StringVar(&maxSize, "max-size", "", "--max-size=512MB to limit the total uncompressed size of the project")
+	cmd.Flags().IntVar(&opts.MaxFiles, "max-files", project.DefaultMaxFiles, "--max-files=20000 to limit the number of the project's files")
+	cmd.Flags().StringSliceVar(&opts.RewriteFiles, "rewrite-files", project.DefaultRewriteFiles, "--rewrite-files=*.md,Dockerfile glob patterns of the non-Go files to replace the module path")
+	cmd.Flags().BoolVar(&opts.NoVerify, "no-verify", opts.NoVerify, "--no-verify to skip the \"go list\" check after the module path is rewritten")
+	cmd.Flags().StringVar(&answersFile, "answers", "", "--answers=answers.yml or answers.json to answer the project, version, module, dest and template variables questions")
+	cmd.Flags().BoolVarP(&acceptDefaults, "yes", "y", acceptDefaults, "--yes to accept the default values of the questions which are not answered")
+	cmd.Flags().StringVar(&onConflict, "on-conflict", "", "--on-conflict=skip, backup or new for the files which already exist in the destination directory, defaults to backup or a question per file")
+	cmd.Flags().StringToStringVar(&opts.Conflicts, "conflict", nil, "--conflict=go.mod=skip,*.md=new to set the conflict strategy per file")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", dryRun, "--dry-run to preview the files, the rewrites and the project file without installing")
+	cmd.Flags().BoolVar(&reg.Verify, "verify", reg.Verify, "--verify to refuse an unsigned registry or a project archive without a matching pinned checksum")
+	cmd.Flags().BoolVar(&opts.Git, "git", opts.Git, "--git to clone the repository at a branch, tag or commit instead of downloading its archive")
+	cmd.Flags().StringVar(&project.DefaultGitSource.Token, "git-token", "", "--git-token=TOKEN for private HTTPS repositories, defaults to the "+project.GitTokenEnv+" environment variable")
 
 	return cmd
 }
@@ -128,3 +308,145 @@ func formatByteLength(b int) string {
 	return fmt.Sprintf("%.1f %cB",
 		float64(b)/float64(div), "kMGTPE"[exp])
 }
+
+func printDryRun(cmd *cobra.Command, result *project.DryRunResult) {
+	cmd.Printf("Dry run, nothing is written to <%s>.\n\n", result.Dest)
+
+	var created, overwritten, skipped int
+	for _, f := range result.Files {
+		name := f.Name
+		if f.Dir {
+			name += "/"
+		}
+
+		action := "create"
+		switch f.Conflict {
+		case project.ConflictSkip:
+			action = "skip"
+			skipped++
+		case project.ConflictBackup:
+			action = "overwrite"
+			if !f.Dir {
+				overwritten++
+			} else {
+				created++
+			}
+			name += fmt.Sprintf(" (backup: %s)", f.Backup)
+		default:
+			created++
+		}
+
+		if f.Diff != "" {
+			name += " (rewritten)"
+		}
+
+		cmd.Printf("  %-9s %s\n", action, name)
+	}
+	cmd.Printf("\n%d to create, %d to overwrite, %d to skip.\n", created, overwritten, skipped)
+
+	for _, f := range result.Files {
+		if f.Diff != "" {
+			cmd.Printf("\n%s", f.Diff)
+		}
+	}
+
+	cmd.Printf("\n%s:\n%s", result.ProjectFilename, result.ProjectFile)
+}
+
+// askConflict returns a `Project.ResolveConflict` which asks for the strategy of each existing file,
+// a strategy chosen for all files is stored to the "all".
+func askConflict(all *string) func(name string) (string, error) {
+	options := []struct {
+		title    string
+		strategy string
+		all      bool
+	}{
+		{"Overwrite, keep a backup", project.ConflictBackup, false},
+		{"Skip", project.ConflictSkip, false},
+		{"Write a " + project.NewExt + " file", project.ConflictNew, false},
+		{"Overwrite all, keep backups", project.ConflictBackup, true},
+		{"Skip all", project.ConflictSkip, true},
+		{"Write " + project.NewExt + " files for all", project.ConflictNew, true},
+	}
+
+	titles := make([]string, 0, len(options))
+	for _, option := range options {
+		titles = append(titles, option.title)
+	}
+
+	return func(name string) (string, error) {
+		if *all != "" {
+			return *all, nil
+		}
+
+		var answer int
+		err := survey.AskOne(&survey.Select{Message: fmt.Sprintf("File <%s> already exists:", name), Options: titles}, &answer)
+		if err != nil {
+			return "", err
+		}
+
+		option := options[answer]
+		if option.all {
+			*all = option.strategy
+		}
+
+		return option.strategy, nil
+	}
+}
+
+// newAnswers is the answers file of the new command.
+//
+// Example:
+//
+//	project: basic
+//	version: v1.0.0
+//	module: github.com/author/app
+//	dest: ./app
+//	variables:
+//	  author: kataras
+//	  docker: true
+type newAnswers struct {
+	Project   string                 `json:"project,omitempty" yaml:"project,omitempty"`
+	Version   string                 `json:"version,omitempty" yaml:"version,omitempty"`
+	Module    string                 `json:"module,omitempty" yaml:"module,omitempty"`
+	Dest      string                 `json:"dest,omitempty" yaml:"dest,omitempty"`
+	Variables map[string]interface{} `json:"variables,omitempty" yaml:"variables,omitempty"`
+}
+
+// loadNewAnswers fills the "opts" from the answers file and marks the answered keys.
+func loadNewAnswers(file string, opts *project.Project, answered map[string]struct{}) error {
+	var answers newAnswers
+	if err := utils.Import(file, &answers); err != nil {
+		return fmt.Errorf("answers: %v", err)
+	}
+
+	for _, a := range []struct {
+		key   string
+		value string
+		dest  *string
+	}{
+		{"project", answers.Project, &opts.Name},
+		{"version", answers.Version, &opts.Version},
+		{"module", answers.Module, &opts.Module},
+		{"dest", answers.Dest, &opts.Dest},
+	} {
+		if a.value != "" {
+			*a.dest = a.value
+			answered[a.key] = struct{}{}
+		}
+	}
+
+	if len(answers.Variables) > 0 {
+		opts.Answers = answers.Variables
+	}
+
+	return nil
+}
+
+func missingAnswersError(keys []string) error {
+	return fmt.Errorf("missing answers: %s\nprovide them through --answers or use --yes to accept the defaults", strings.Join(keys, ", "))
+}
+
+func isTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
@@ -0,0 +1,448 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kataras/iris-cli/project"
+	"github.com/kataras/iris-cli/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// iris-cli registry list --tag=mvc
+// iris-cli registry search admin
+// iris-cli registry show go-admin
+// iris-cli registry add myapp owner/repo --description="My template" --tag=mvc
+// iris-cli registry remove myapp
+// iris-cli registry validate ./registry.yml
+// iris-cli registry serve --dir=./templates --addr=localhost:8080
+func registryCommand() *cobra.Command {
+	var (
+		registries []string
+	)
+
+	cmd := &cobra.Command{
+		Use:           "registry",
+		Short:         "Browse and maintain the project registries",
+		SilenceErrors: true,
+	}
+
+	cmd.PersistentFlags().StringArrayVar(&registries, "registry", nil, "--registry=URL or local file, or namespace=URL, repeat it to merge more registries, the first one has the highest priority")
+
+	cmd.AddCommand(registryListCommand(&registries))
+	cmd.AddCommand(registrySearchCommand(&registries))
+	cmd.AddCommand(registryShowCommand(&registries))
+	cmd.AddCommand(registryAddCommand())
+	cmd.AddCommand(registryRemoveCommand())
+	cmd.AddCommand(registryValidateCommand())
+	cmd.AddCommand(registryServeCommand())
+
+	return cmd
+}
+
+func registryListCommand(registries *[]string) *cobra.Command {
+	var (
+		tag string
+	)
+
+	cmd := &cobra.Command{
+		Use:           "list",
+		Short:         "List the projects of the registries",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reg, err := openRegistry(cmd, *registries)
+			if err != nil {
+				return err
+			}
+
+			printRegistryProjects(cmd, reg, reg.Filter(tag))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&tag, "tag", "", "--tag=mvc to list the projects with that tag only")
+
+	return cmd
+}
+
+func registrySearchCommand(registries *[]string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "search <term>",
+		Short:         "Search the projects by name, description, tag or repository",
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reg, err := openRegistry(cmd, *registries)
+			if err != nil {
+				return err
+			}
+
+			term := strings.ToLower(args[0])
+			var names []string
+			for _, name := range reg.Names {
+				p := reg.Projects[name]
+				if strings.Contains(strings.ToLower(name), term) || strings.Contains(strings.ToLower(p.Description), term) ||
+					strings.Contains(strings.ToLower(p.Repo), term) || p.HasTag(term) {
+					names = append(names, name)
+				}
+			}
+
+			printRegistryProjects(cmd, reg, names)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func registryShowCommand(registries *[]string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "show <name>",
+		Short:         "Show the details and the available versions of a project",
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reg, err := openRegistry(cmd, *registries)
+			if err != nil {
+				return err
+			}
+
+			name := args[0]
+			p, ok := reg.Projects[name]
+			if !ok {
+				return fmt.Errorf("project <%s> is not available", name)
+			}
+
+			iris := ""
+			if p.IrisVersion > 0 {
+				iris = fmt.Sprintf("v%d", p.IrisVersion)
+			}
+
+			cmd.Printf("Name: %s\n", name)
+			cmd.Printf("Repo: %s\n", p.Repo)
+			for _, field := range []struct {
+				title string
+				value string
+			}{
+				{"Description", p.Description},
+				{"Tags", strings.Join(p.Tags, ", ")},
+				{"Go", p.GoVersion},
+				{"Iris", iris},
+				{"Default branch", p.DefaultBranch},
+				{"Maintainers", strings.Join(p.Maintainers, ", ")},
+				{"Source", p.Source},
+			} {
+				if field.value != "" {
+					cmd.Printf("%s: %s\n", field.title, field.value)
+				}
+			}
+
+			var versions []string
+			if offline {
+				versions = reg.Cache.Versions(p.Repo)
+			} else {
+				versions = project.ListVersions(p.Repo)
+			}
+
+			if len(versions) == 0 {
+				cmd.Println("Versions: none")
+				return nil
+			}
+
+			cmd.Println("Versions:")
+			for _, version := range versions {
+				if sum, ok := reg.Checksums[name+"@"+version]; ok {
+					cmd.Printf("• %s (%s)\n", version, sum)
+					continue
+				}
+				cmd.Printf("• %s\n", version)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func registryAddCommand() *cobra.Command {
+	var (
+		file = project.DefaultLocalRegistryFile()
+		p    project.RegistryProject
+	)
+
+	cmd := &cobra.Command{
+		Use:           "add <name> <repo>",
+		Short:         "Add or replace a project of the local registry file",
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			p.Repo = args[1]
+
+			repo, _ := project.SplitSubdir(p.Repo)
+			if _, _, err := project.ParseSource(repo); err != nil {
+				return err
+			}
+
+			reg, err := project.LoadRegistryFile(file)
+			if err != nil {
+				return err
+			}
+
+			reg.Projects[name] = &p
+			if err = reg.SaveFile(file); err != nil {
+				return err
+			}
+
+			cmd.Printf("Project <%s> added to <%s>\n", name, file)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", file, "--file=registry.yml the local registry file, its projects override the ones of the other registries")
+	cmd.Flags().StringVar(&p.Description, "description", "", "--description=\"Admin dashboard\" the project's description")
+	cmd.Flags().StringSliceVar(&p.Tags, "tag", nil, "--tag=admin,mvc the project's tags")
+	cmd.Flags().StringVar(&p.GoVersion, "go-version", "", "--go-version=1.21 the minimum Go version")
+	cmd.Flags().IntVar(&p.IrisVersion, "iris-version", 0, "--iris-version=12 the Iris major version")
+	cmd.Flags().StringVar(&p.DefaultBranch, "default-branch", "", "--default-branch=master the version to install when no version is specified")
+	cmd.Flags().StringSliceVar(&p.Maintainers, "maintainer", nil, "--maintainer=kataras the project's maintainers")
+
+	return cmd
+}
+
+func registryRemoveCommand() *cobra.Command {
+	var (
+		file = project.DefaultLocalRegistryFile()
+	)
+
+	cmd := &cobra.Command{
+		Use:           "remove <name>",
+		Short:         "Remove a project of the local registry file",
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			reg, err := project.LoadRegistryFile(file)
+			if err != nil {
+				return err
+			}
+
+			if _, ok := reg.Projects[name]; !ok {
+				return fmt.Errorf("project <%s> does not exist in <%s>", name, file)
+			}
+
+			delete(reg.Projects, name)
+			for key := range reg.Checksums {
+				if strings.HasPrefix(key, name+"@") {
+					delete(reg.Checksums, key)
+				}
+			}
+
+			if err = reg.SaveFile(file); err != nil {
+				return err
+			}
+
+			cmd.Printf("Project <%s> removed from <%s>\n", name, file)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", file, "--file=registry.yml the local registry file")
+
+	return cmd
+}
+
+func registryValidateCommand() *cobra.Command {
+	var (
+		noArchives bool
+	)
+
+	cmd := &cobra.Command{
+		Use:           "validate <file>",
+		Short:         "Check the schema, the project names and the archives of a registry file",
+		SilenceErrors: true,
+		Args:          cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reg := project.NewRegistry()
+			reg.Endpoint = args[0]
+			reg.Cache, reg.Offline = project.NewCache(""), offline
+
+			issues, err := reg.Validate(!noArchives)
+			if err != nil {
+				return err
+			}
+
+			if len(issues) > 0 {
+				for _, issue := range issues {
+					cmd.Printf("• %v\n", issue)
+				}
+				return fmt.Errorf("registry <%s>: %d issues found", reg.Endpoint, len(issues))
+			}
+
+			cmd.Printf("Registry <%s> is valid\n", reg.Endpoint)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&noArchives, "no-archives", noArchives, "--no-archives to skip the download of the projects' archives to check their go.mod file")
+
+	return cmd
+}
+
+func registryServeCommand() *cobra.Command {
+	var (
+		dir  = "."
+		addr = "localhost:8080"
+	)
+
+	cmd := &cobra.Command{
+		Use:           "serve",
+		Short:         "Serve the local template directories as a registry",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !utils.IsDir(dir) {
+				return fmt.Errorf("directory <%s> does not exist", dir)
+			}
+
+			srv := project.NewRegistryServer(dir)
+			baseURL := "http://" + addr
+			if strings.HasPrefix(addr, ":") {
+				baseURL = "http://localhost" + addr
+			}
+
+			reg, err := srv.Registry(baseURL)
+			if err != nil {
+				return err
+			}
+
+			printRegistryProjects(cmd, reg, reg.Names)
+			cmd.Printf("Serving <%s> at <%s/registry.yml>\n", dir, baseURL)
+			cmd.Printf("Install its projects with: iris-cli new --registry=%s/registry.yml\n", baseURL)
+
+			return http.ListenAndServe(addr, srv)
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", dir, "--dir=./templates the directory of the templates, each one of its directories is a project")
+	cmd.Flags().StringVar(&addr, "addr", addr, "--addr=localhost:8080 the address to listen on")
+
+	return cmd
+}
+
+// openRegistry loads the registries of the "flags" or the user configuration.
+func openRegistry(cmd *cobra.Command, flags []string) (*project.Registry, error) {
+	reg := project.NewRegistry()
+	reg.Cache, reg.Offline = project.NewCache(""), offline
+
+	userConfig, err := project.LoadUserConfig("")
+	if err != nil {
+		return nil, err
+	}
+
+	if reg.TrustedKeys, err = userConfig.PublicKeys(); err != nil {
+		return nil, fmt.Errorf("user config: %v", err)
+	}
+
+	if err = loadRegistry(cmd, reg, flags, userConfig); err != nil {
+		return nil, err
+	}
+
+	return reg, nil
+}
+
+func printRegistryProjects(cmd *cobra.Command, reg *project.Registry, names []string) {
+	if len(names) == 0 {
+		cmd.Println("no projects found")
+		return
+	}
+
+	for _, name := range names {
+		p := reg.Projects[name]
+		line := fmt.Sprintf("• %s: %s", name, p.Repo)
+		if description := describeProject(p); description != "" {
+			line += " - " + description
+		}
+
+		if len(reg.Sources) > 1 {
+			line += " <" + p.Source + ">"
+		}
+
+		cmd.Println(line)
+	}
+}
+
+// registrySources returns the registries of the "flags", the first one has the highest priority,
+// or the registries of the user configuration. The local registry file, if exists, overrides them.
+// It returns nil for the default registry.
+func registrySources(flags []string, userConfig *project.UserConfig) []*project.RegistrySource {
+	sources := userConfig.Registries
+	if len(flags) > 0 {
+		sources = make([]*project.RegistrySource, 0, len(flags))
+		for i, s := range flags {
+			src := project.ParseRegistrySource(s)
+			src.Priority = len(flags) - i
+			sources = append(sources, src)
+		}
+	}
+
+	localFile := project.DefaultLocalRegistryFile()
+	if !utils.Exists(localFile) {
+		return sources
+	}
+
+	if len(sources) == 0 {
+		sources = []*project.RegistrySource{{Endpoint: project.DefaultRegistryEndpoint}}
+	}
+
+	priority := 0
+	for _, src := range sources {
+		if src.Priority >= priority {
+			priority = src.Priority + 1
+		}
+	}
+
+	return append([]*project.RegistrySource{{Endpoint: localFile, Priority: priority, Override: true}}, sources...)
+}
+
+// loadRegistry loads the registries of the "flags" or the user configuration.
+func loadRegistry(cmd *cobra.Command, reg *project.Registry, flags []string, userConfig *project.UserConfig) error {
+	reg.Sources = registrySources(flags, userConfig)
+
+	if len(reg.Sources) == 0 {
+		cmd.Printf("Loading projects from <%s>\n", reg.Endpoint)
+	} else {
+		endpoints := make([]string, 0, len(reg.Sources))
+		for _, src := range reg.Sources {
+			endpoints = append(endpoints, src.Endpoint)
+		}
+		cmd.Printf("Loading projects from <%s>\n", strings.Join(endpoints, ">, <"))
+	}
+
+	return reg.Load()
+}
+
+// describeProject returns the description of a registry's project and its metadata, as shown in the projects select.
+func describeProject(p *project.RegistryProject) string {
+	var info []string
+	if len(p.Tags) > 0 {
+		info = append(info, strings.Join(p.Tags, ", "))
+	}
+
+	if p.IrisVersion > 0 {
+		info = append(info, fmt.Sprintf("iris v%d", p.IrisVersion))
+	}
+
+	if p.GoVersion != "" {
+		info = append(info, "go "+p.GoVersion+"+")
+	}
+
+	if len(info) == 0 {
+		return p.Description
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("%s [%s]", p.Description, strings.Join(info, " | ")))
+}
@@ -10,7 +10,14 @@ import (
 )
 
 // iris-cli --time-format=http -v run basic
+// iris-cli run --registry=./mirror/registry.yml basic
+// iris-cli run --profile=staging
 func runCommand() *cobra.Command {
+	var (
+		registries []string
+		profile    string
+	)
+
 	cmd := &cobra.Command{
 		Use:           "run",
 		Short:         "Run starts a project",
@@ -38,7 +45,12 @@ func runCommand() *cobra.Command {
 						}
 
 						if doInstall {
-							if err := RunCommand(cmd, "new", name /* , "--registry=./_testfiles/registry.yml" */); err != nil {
+							newArgs := []string{name}
+							for _, r := range registries {
+								newArgs = append(newArgs, "--registry="+r)
+							}
+
+							if err := RunCommand(cmd, "new", newArgs...); err != nil {
 								return err
 							}
 
@@ -60,9 +72,13 @@ func runCommand() *cobra.Command {
 				return err
 			}
 
+			p.Profile = profile
 			return p.Run(cmd.OutOrStdout(), cmd.ErrOrStderr())
 		},
 	}
 
+	cmd.Flags().StringVar(&profile, "profile", "", "--profile=staging the project profile of the environment variables, .env files and build tags")
+	cmd.Flags().StringArrayVar(&registries, "registry", nil, "--registry=URL or local file, or namespace=URL, to install a missing project from, repeat it to merge more registries")
+
 	return cmd
 }
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/kataras/iris-cli/project"
+
+	"github.com/spf13/cobra"
+)
+
+// iris-cli sign --generate-key=registry.key
+// iris-cli sign --key=registry.key registry.yml
+func signCommand() *cobra.Command {
+	var (
+		keyFile     string
+		generateKey string
+	)
+
+	cmd := &cobra.Command{
+		Use:           "sign",
+		Short:         "Sign a registry file with an ed25519 private key",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if generateKey != "" {
+				publicKey, privateKey, err := project.GenerateKey()
+				if err != nil {
+					return err
+				}
+
+				if err = ioutil.WriteFile(generateKey, []byte(privateKey+"\n"), 0600); err != nil {
+					return err
+				}
+
+				if err = ioutil.WriteFile(generateKey+".pub", []byte(publicKey+"\n"), 0644); err != nil {
+					return err
+				}
+
+				cmd.Printf("Private key saved to <%s>, keep it secret.\n", generateKey)
+				cmd.Printf("Public key: %s\nAdd it to the TrustedKeys of <%s> to verify the registries signed by this key.\n", publicKey, project.DefaultUserConfigFile())
+				return nil
+			}
+
+			if len(args) == 0 {
+				return fmt.Errorf("registry file is missing")
+			}
+
+			if keyFile == "" {
+				return fmt.Errorf("--key is required")
+			}
+
+			b, err := ioutil.ReadFile(keyFile)
+			if err != nil {
+				return err
+			}
+
+			privateKey, err := project.ParsePrivateKey(string(b))
+			if err != nil {
+				return err
+			}
+
+			for _, registryFile := range args {
+				body, err := ioutil.ReadFile(registryFile)
+				if err != nil {
+					return err
+				}
+
+				sigFile := registryFile + project.SignatureExt
+				if err = ioutil.WriteFile(sigFile, project.Sign(body, privateKey), 0644); err != nil {
+					return err
+				}
+
+				cmd.Printf("Signature saved to <%s>\n", sigFile)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&keyFile, "key", "", "--key=registry.key the private key file to sign with")
+	cmd.Flags().StringVar(&generateKey, "generate-key", "", "--generate-key=registry.key to generate a new key pair, the public key is saved to registry.key.pub")
+
+	return cmd
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/kataras/iris-cli/project"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// askTemplate prompts for the template variables which are not already answered.
+func askTemplate(t *project.Template, answers map[string]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(t.Variables))
+	for k, v := range answers {
+		result[k] = v
+	}
+
+	for _, v := range t.Variables {
+		if _, ok := result[v.Name]; ok {
+			continue
+		}
+
+		var (
+			prompt survey.Prompt
+			value  interface{}
+			def    = v.DefaultValue()
+		)
+
+		switch v.Type {
+		case project.VarBool:
+			b, _ := strconv.ParseBool(fmt.Sprintf("%v", def))
+			prompt = &survey.Confirm{Message: v.Prompt, Default: b, Help: v.Help}
+			value = new(bool)
+		case project.VarChoice:
+			prompt = &survey.Select{Message: v.Prompt, Options: v.Options, Default: fmt.Sprintf("%v", def), Help: v.Help}
+			value = new(string)
+		default:
+			prompt = &survey.Input{Message: v.Prompt, Default: fmt.Sprintf("%v", def), Help: v.Help}
+			value = new(string)
+		}
+
+		validate := func(ans interface{}) error {
+			if s, ok := ans.(string); ok {
+				_, err := v.Parse(s)
+				return err
+			}
+
+			return nil
+		}
+
+		if err := survey.AskOne(prompt, value, survey.WithValidator(validate)); err != nil {
+			return nil, err
+		}
+
+		switch value := value.(type) {
+		case *bool:
+			result[v.Name] = *value
+		case *string:
+			result[v.Name] = *value
+		}
+	}
+
+	return t.Answers(result)
+}
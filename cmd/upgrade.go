@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/kataras/iris-cli/project"
+	"github.com/kataras/iris-cli/utils"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+// iris-cli upgrade
+// iris-cli upgrade --version=v2.0.0 ./myapp
+// iris-cli upgrade --on-conflict=skip
+// iris-cli upgrade --from=v1.0.0 --version=v2.0.0
+func upgradeCommand() *cobra.Command {
+	var (
+		version    string
+		from       string
+		onConflict string
+		conflicts  map[string]string
+	)
+
+	cmd := &cobra.Command{
+		Use:           "upgrade",
+		Short:         "Upgrade a project to a newer version of its template, local changes are merged",
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := "." // current directory.
+			if len(args) > 0 {
+				name = args[0]
+			}
+
+			projectPath, err := filepath.Abs(name)
+			if err != nil {
+				return err
+			}
+
+			if !utils.Exists(projectPath) {
+				return project.ErrProjectNotExists
+			}
+
+			p, err := project.LoadFromDisk(projectPath)
+			if err != nil {
+				return err
+			}
+
+			if p.Repo == "" {
+				return fmt.Errorf("project <%s> was not installed from a template", projectPath)
+			}
+
+			p.Dest = projectPath
+			p.Cache, p.Offline = project.NewCache(""), offline
+			p.Prompt = askTemplate
+
+			strategies := []string{onConflict}
+			for _, strategy := range conflicts {
+				strategies = append(strategies, strategy)
+			}
+
+			for _, strategy := range strategies {
+				if strategy != "" && !project.IsConflictStrategy(strategy) {
+					return fmt.Errorf("unknown conflict strategy: %s, expected one of: %s", strategy, strings.Join(project.ConflictStrategies, ", "))
+				}
+			}
+
+			// The existing files which are not project files, e.g. skipped on install.
+			p.OnConflict, p.Conflicts = onConflict, conflicts
+			if onConflict == "" && isTerminal() {
+				p.ResolveConflict = askConflict(&p.OnConflict)
+			}
+
+			if version == "" {
+				var availableVersions []string
+				if offline {
+					availableVersions = p.Cache.Versions(p.Repo)
+				} else {
+					availableVersions = project.ListVersions(p.Repo)
+				}
+
+				switch len(availableVersions) {
+				case 0:
+					version = "main"
+				case 1:
+					version = availableVersions[0]
+				default:
+					if err = survey.AskOne(&survey.Select{Message: fmt.Sprintf("Upgrade from <%s> to version:", p.Version), Options: availableVersions, PageSize: 5}, &version); err != nil {
+						return err
+					}
+				}
+			}
+
+			fromVersion := p.Version
+			if from != "" {
+				fromVersion = from
+			}
+
+			cmd.Printf("Upgrading <%s> from <%s> to <%s>\n", p.Name, fromVersion, version)
+			result, err := p.Upgrade(version, from)
+			if err != nil {
+				return err
+			}
+
+			cmd.Print(result)
+			if n := len(result.Conflicts); n > 0 {
+				cmd.Printf("%d file(s) with conflicts, please resolve them manually.\n", n)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&version, "version", "", "--version=v2.0.0 the template version to upgrade to, defaults to a prompt of the available versions")
+	cmd.Flags().StringVar(&from, "from", "", "--from=v1.0.0 the installed template version, defaults to the commit recorded on install")
+	cmd.Flags().StringVar(&onConflict, "on-conflict", "", "--on-conflict=skip, backup or new for the template files which exist but are not project files, defaults to backup or a question per file")
+	cmd.Flags().StringToStringVar(&conflicts, "conflict", nil, "--conflict=go.mod=skip,*.md=new to set the conflict strategy per file")
+
+	return cmd
+}
@@ -0,0 +1,165 @@
+package project
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+// archiveFile is a single entry of a zip or a tar.gz archive.
+type archiveFile struct {
+	Name string // slash-separated, directories end with a slash.
+	Mode os.FileMode
+	// Link is the target of a tar symbolic or hard link,
+	// zip archives store the target of a symbolic link as the entry's contents instead.
+	Link string
+	// Hardlink reports a tar hard link whose target is not a previous regular entry of the archive,
+	// the rest of them are read as copies of their target.
+	Hardlink bool
+	open     func() (io.ReadCloser, error)
+}
+
+func (f *archiveFile) IsDir() bool {
+	return f.Mode.IsDir()
+}
+
+func (f *archiveFile) Open() (io.ReadCloser, error) {
+	return f.open()
+}
+
+// readArchive returns the entries of a "format" compressed "body".
+// The "maxSize" limits the total uncompressed size of tar.gz archives, which are read into memory,
+// zip entries are decompressed lazily on `archiveFile.Open`.
+func readArchive(body []byte, format string, maxSize int64) ([]*archiveFile, error) {
+	switch format {
+	case ArchiveZip, "":
+		return readZip(body)
+	case ArchiveTarGz:
+		return readTarGz(body, maxSize)
+	default:
+		return nil, fmt.Errorf("unknown archive format: %s", format)
+	}
+}
+
+func readZip(body []byte) ([]*archiveFile, error) {
+	r, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*archiveFile, 0, len(r.File))
+	for _, f := range r.File {
+		files = append(files, &archiveFile{
+			Name: f.Name,
+			Mode: f.Mode(),
+			open: f.Open,
+		})
+	}
+
+	return files, nil
+}
+
+func readTarGz(body []byte, maxSize int64) ([]*archiveFile, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	var size int64
+
+	gr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	var files []*archiveFile
+
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		switch h.Typeflag {
+		case tar.TypeXGlobalHeader, tar.TypeXHeader: // e.g. GitHub's pax_global_header.
+			continue
+		case tar.TypeDir:
+			name := h.Name
+			if !strings.HasSuffix(name, "/") {
+				name += "/"
+			}
+
+			files = append(files, &archiveFile{Name: name, Mode: os.ModeDir | os.FileMode(h.Mode).Perm()})
+			continue
+		case tar.TypeLink:
+			// A hard link of a previous regular entry is extracted as a copy of it.
+			if target := findArchiveFile(files, path.Clean(h.Linkname)); target != nil {
+				files = append(files, &archiveFile{Name: h.Name, Mode: target.Mode, open: target.open})
+				continue
+			}
+
+			files = append(files, &archiveFile{Name: h.Name, Mode: h.FileInfo().Mode(), Link: h.Linkname, Hardlink: true})
+			continue
+		case tar.TypeSymlink:
+			files = append(files, &archiveFile{
+				Name: h.Name,
+				Mode: h.FileInfo().Mode(),
+				Link: h.Linkname,
+			})
+			continue
+		}
+
+		contents, err := ioutil.ReadAll(io.LimitReader(tr, maxSize-size+1))
+		if err != nil {
+			return nil, err
+		}
+
+		if size += int64(len(contents)); size > maxSize {
+			return nil, &ExtractError{Name: h.Name, Err: ErrMaxSize}
+		}
+
+		files = append(files, &archiveFile{
+			Name: h.Name,
+			Mode: h.FileInfo().Mode(),
+			open: func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(bytes.NewReader(contents)), nil
+			},
+		})
+	}
+
+	return files, nil
+}
+
+// archiveRoot returns the common root folder of the archive "files", e.g. "iris-main/"
+// or empty if the files are not placed under a single root folder.
+func archiveRoot(files []*archiveFile) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	first := files[0].Name
+	idx := strings.IndexByte(first, '/')
+	if idx <= 0 {
+		return ""
+	}
+
+	root := first[:idx+1]
+	for _, f := range files[1:] {
+		if !strings.HasPrefix(f.Name, root) {
+			return ""
+		}
+	}
+
+	return root
+}
@@ -0,0 +1,277 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CacheDirEnv is the environment variable which overrides the default cache directory.
+const CacheDirEnv = "IRIS_CLI_CACHE_DIR"
+
+// Cache entry kinds.
+const (
+	CacheTemplate = "template"
+	CacheRegistry = "registry"
+)
+
+// ErrNotCached is returned on offline mode when a project's archive (or a registry) is not available in the cache.
+var ErrNotCached = errors.New("not available in the cache")
+
+// DefaultCacheDir returns the IRIS_CLI_CACHE_DIR environment variable
+// or the "iris-cli" directory under the user's cache directory.
+func DefaultCacheDir() string {
+	if dir := os.Getenv(CacheDirEnv); dir != "" {
+		return dir
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "iris-cli")
+}
+
+// CacheEntry describes a cached archive.
+type CacheEntry struct {
+	Kind    string    `json:"kind"`
+	Repo    string    `json:"repo"` // or the registry endpoint.
+	Version string    `json:"version,omitempty"`
+	Commit  string    `json:"commit,omitempty"`
+	Format  string    `json:"format,omitempty"`
+	Digest  string    `json:"digest"` // the sha256 of the contents, the name of the blob file.
+	Size    int64     `json:"size"`
+	Time    time.Time `json:"time"`
+}
+
+// Cache is a local content-addressed store of the downloaded template archives and registry files.
+// The contents are stored under the "blobs" directory by their SHA-256 digest
+// and the "index.json" file maps a repository, version and resolved commit to a digest.
+type Cache struct {
+	Dir string
+
+	mu sync.Mutex
+}
+
+// NewCache returns a new cache which is stored inside the "dir" directory.
+// If "dir" is empty then the `DefaultCacheDir` is used instead.
+func NewCache(dir string) *Cache {
+	if dir == "" {
+		dir = DefaultCacheDir()
+	}
+
+	return &Cache{Dir: dir}
+}
+
+func (c *Cache) indexFile() string {
+	return filepath.Join(c.Dir, "index.json")
+}
+
+func (c *Cache) blobFile(digest string) string {
+	return filepath.Join(c.Dir, "blobs", digest)
+}
+
+func (c *Cache) readIndex() ([]*CacheEntry, error) {
+	b, err := ioutil.ReadFile(c.indexFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entries []*CacheEntry
+	if err = json.Unmarshal(b, &entries); err != nil {
+		return nil, fmt.Errorf("cache: index: %v", err)
+	}
+
+	return entries, nil
+}
+
+func (c *Cache) writeIndex(entries []*CacheEntry) error {
+	b, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(c.Dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(c.indexFile(), b, 0644)
+}
+
+// List returns the cached entries, sorted by repository and most recent first.
+func (c *Cache) List() ([]*CacheEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Repo != entries[j].Repo {
+			return entries[i].Repo < entries[j].Repo
+		}
+
+		return entries[i].Time.After(entries[j].Time)
+	})
+
+	return entries, nil
+}
+
+// Get returns the most recent cached entry and its contents of "repo" at "version".
+// If "commit" is not empty then the entry's resolved commit should match it too.
+func (c *Cache) Get(kind, repo, version, commit string) (*CacheEntry, []byte, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.Kind != kind || entry.Repo != repo {
+			continue
+		}
+
+		if commit != "" {
+			if entry.Commit != commit {
+				continue
+			}
+		} else if entry.Version != version {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(c.blobFile(entry.Digest))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, nil, err
+		}
+
+		if sum := sha256.Sum256(b); hex.EncodeToString(sum[:]) != entry.Digest {
+			continue // corrupted.
+		}
+
+		return entry, b, nil
+	}
+
+	return nil, nil, ErrNotCached
+}
+
+// Put stores the "body" contents and adds or replaces its index entry.
+func (c *Cache) Put(entry *CacheEntry, body []byte) error {
+	sum := sha256.Sum256(body)
+	entry.Digest = hex.EncodeToString(sum[:])
+	entry.Size = int64(len(body))
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	blobFile := c.blobFile(entry.Digest)
+	if err := os.MkdirAll(filepath.Dir(blobFile), os.ModePerm); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(blobFile, body, 0644); err != nil {
+		return err
+	}
+
+	entries, err := c.readIndex()
+	if err != nil {
+		return err
+	}
+
+	for i, e := range entries {
+		if e.Kind == entry.Kind && e.Repo == entry.Repo && e.Version == entry.Version && e.Commit == entry.Commit {
+			entries = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+
+	return c.writeIndex(append(entries, entry))
+}
+
+// Versions returns the cached versions of a "repo".
+func (c *Cache) Versions(repo string) []string {
+	repo, _ = SplitSubdir(repo)
+
+	entries, err := c.List()
+	if err != nil {
+		return nil
+	}
+
+	var versions []string
+	seen := make(map[string]struct{})
+	for _, entry := range entries {
+		if entry.Kind != CacheTemplate || entry.Repo != repo {
+			continue
+		}
+
+		if _, ok := seen[entry.Version]; ok {
+			continue
+		}
+		seen[entry.Version] = struct{}{}
+		versions = append(versions, entry.Version)
+	}
+
+	return versions
+}
+
+// Prune removes the entries which are older than "maxAge" or all if "maxAge" is zero,
+// it removes their blob files when they are not referenced by any other entry.
+// It returns the removed entries.
+func (c *Cache) Prune(maxAge time.Duration) ([]*CacheEntry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	var kept, removed []*CacheEntry
+	for _, entry := range entries {
+		if maxAge > 0 && time.Since(entry.Time) < maxAge {
+			kept = append(kept, entry)
+			continue
+		}
+
+		removed = append(removed, entry)
+	}
+
+	referenced := make(map[string]struct{}, len(kept))
+	for _, entry := range kept {
+		referenced[entry.Digest] = struct{}{}
+	}
+
+	for _, entry := range removed {
+		if _, ok := referenced[entry.Digest]; ok {
+			continue
+		}
+
+		if err = os.Remove(c.blobFile(entry.Digest)); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	if err = c.writeIndex(kept); err != nil {
+		return nil, err
+	}
+
+	return removed, nil
+}
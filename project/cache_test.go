@@ -0,0 +1,62 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := NewCache(dir)
+
+	if _, _, err = c.Get(CacheTemplate, "owner/repo", "main", ""); err != ErrNotCached {
+		t.Fatalf("expected error: %v but got: %v", ErrNotCached, err)
+	}
+
+	entry := &CacheEntry{Kind: CacheTemplate, Repo: "owner/repo", Version: "main", Commit: "4f680143493ee70bd4641495be16e77679c6d259", Format: ArchiveZip}
+	if err = c.Put(entry, []byte("archive")); err != nil {
+		t.Fatal(err)
+	}
+
+	// By version (offline).
+	got, b, err := c.Get(CacheTemplate, "owner/repo", "main", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "archive", string(b); expected != got {
+		t.Fatalf("expected contents: %q but got: %q", expected, got)
+	}
+
+	if expected, got := entry.Commit, got.Commit; expected != got {
+		t.Fatalf("expected commit: %q but got: %q", expected, got)
+	}
+
+	// By resolved commit.
+	if _, _, err = c.Get(CacheTemplate, "owner/repo", "main", "904be887fbd03ce5c34c6290173a119d9d1acd49"); err != ErrNotCached {
+		t.Fatalf("expected error: %v for a different commit but got: %v", ErrNotCached, err)
+	}
+
+	if expected, got := []string{"main"}, c.Versions("owner/repo"); len(got) != 1 || got[0] != expected[0] {
+		t.Fatalf("expected versions: %v but got: %v", expected, got)
+	}
+
+	removed, err := c.Prune(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := 1, len(removed); expected != got {
+		t.Fatalf("expected %d removed entries but got %d", expected, got)
+	}
+
+	if _, err = os.Stat(c.blobFile(entry.Digest)); !os.IsNotExist(err) {
+		t.Fatalf("expected blob file to be removed")
+	}
+}
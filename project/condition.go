@@ -0,0 +1,140 @@
+package project
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+)
+
+// parseCondition parses a boolean expression of a template manifest's `Conditions`.
+// The expression uses the Go syntax, e.g. `docker && database == "postgres"`,
+// the identifiers are the names of the template variables.
+func parseCondition(expr string) (ast.Expr, error) {
+	return parser.ParseExpr(expr)
+}
+
+// evalCondition reports whether the "expr" is evaluated as true against the "answers".
+func evalCondition(expr string, answers map[string]interface{}) (bool, error) {
+	x, err := parseCondition(expr)
+	if err != nil {
+		return false, err
+	}
+
+	v, err := evalExpr(x, answers)
+	if err != nil {
+		return false, err
+	}
+
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected a boolean result but got: %v", v)
+	}
+
+	return b, nil
+}
+
+func evalExpr(x ast.Expr, answers map[string]interface{}) (interface{}, error) {
+	switch x := x.(type) {
+	case *ast.ParenExpr:
+		return evalExpr(x.X, answers)
+	case *ast.Ident:
+		switch x.Name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+
+		v, ok := answers[x.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown variable: %s", x.Name)
+		}
+		return v, nil
+	case *ast.BasicLit:
+		switch x.Kind {
+		case token.STRING:
+			return strconv.Unquote(x.Value)
+		case token.INT:
+			return strconv.Atoi(x.Value)
+		}
+	case *ast.UnaryExpr:
+		if x.Op == token.NOT {
+			v, err := evalBool(x.X, answers)
+			if err != nil {
+				return nil, err
+			}
+			return !v, nil
+		}
+	case *ast.BinaryExpr:
+		switch x.Op {
+		case token.LAND, token.LOR:
+			left, err := evalBool(x.X, answers)
+			if err != nil {
+				return nil, err
+			}
+
+			if x.Op == token.LAND && !left || x.Op == token.LOR && left {
+				return left, nil
+			}
+
+			return evalBool(x.Y, answers)
+		case token.EQL, token.NEQ, token.LSS, token.GTR, token.LEQ, token.GEQ:
+			left, err := evalExpr(x.X, answers)
+			if err != nil {
+				return nil, err
+			}
+
+			right, err := evalExpr(x.Y, answers)
+			if err != nil {
+				return nil, err
+			}
+
+			return compare(x.Op, left, right)
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported expression at %d", x.Pos())
+}
+
+func evalBool(x ast.Expr, answers map[string]interface{}) (bool, error) {
+	v, err := evalExpr(x, answers)
+	if err != nil {
+		return false, err
+	}
+
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected a boolean value but got: %v", v)
+	}
+
+	return b, nil
+}
+
+func compare(op token.Token, left, right interface{}) (bool, error) {
+	switch op {
+	case token.EQL:
+		return reflect.DeepEqual(left, right), nil
+	case token.NEQ:
+		return !reflect.DeepEqual(left, right), nil
+	}
+
+	l, ok1 := left.(int)
+	r, ok2 := right.(int)
+	if !ok1 || !ok2 {
+		return false, fmt.Errorf("operator %s expects integer values but got: %v and %v", op, left, right)
+	}
+
+	switch op {
+	case token.LSS:
+		return l < r, nil
+	case token.GTR:
+		return l > r, nil
+	case token.LEQ:
+		return l <= r, nil
+	default: // GEQ.
+		return l >= r, nil
+	}
+}
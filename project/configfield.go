@@ -0,0 +1,529 @@
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/kataras/iris-cli/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigOp is a change of a project file's field, see `Project.EditConfig`.
+type ConfigOp string
+
+const (
+	// ConfigSet replaces the value of the field.
+	ConfigSet ConfigOp = "set"
+	// ConfigAppend appends the values to a list field.
+	ConfigAppend ConfigOp = "append"
+	// ConfigRemove removes the values from a list field.
+	ConfigRemove ConfigOp = "remove"
+	// ConfigUnset removes the field from the project file, so its default value is used.
+	ConfigUnset ConfigOp = "unset"
+)
+
+// configPath resolves a dotted "path" of a project file's field, e.g. watcher.ignore_dirs or livereload.port.
+// The struct fields are matched case-insensitively by their JSON, YAML, TOML or Go names,
+// the rest of the elements are map keys, e.g. profiles.dev.env.DEBUG.
+// It returns the path of the field and its type.
+func configPath(path string) (fieldPath, reflect.Type, error) {
+	if path == "" {
+		return nil, nil, fmt.Errorf("empty field path")
+	}
+
+	var (
+		resolved fieldPath
+		typ      = reflect.TypeOf(Project{})
+		elems    = strings.Split(path, ".")
+	)
+
+	for i, elem := range elems {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		parent := strings.Join(elems[:i], ".")
+		switch typ.Kind() {
+		case reflect.Struct:
+			field, ok := configField(typ, elem)
+			if !ok {
+				if parent == "" {
+					return nil, nil, fmt.Errorf("unknown field: %s, expected one of: %s", elem, strings.Join(configFieldNames(typ), ", "))
+				}
+				return nil, nil, fmt.Errorf("unknown field: %s.%s, expected one of: %s", parent, elem, strings.Join(configFieldNames(typ), ", "))
+			}
+
+			resolved = resolved.field(field.Name)
+			typ = field.Type
+		case reflect.Map:
+			if elem == "" {
+				return nil, nil, fmt.Errorf("%s: empty key", parent)
+			}
+
+			resolved = append(resolved, elem)
+			typ = typ.Elem()
+		default:
+			return nil, nil, fmt.Errorf("%s is not an object, it has no field %s", parent, elem)
+		}
+	}
+
+	return resolved, typ, nil
+}
+
+// configField returns the encoded field of the "typ" struct which matches the "name".
+func configField(typ reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("yaml") == "-" {
+			continue
+		}
+
+		if strings.EqualFold(name, f.Name) {
+			return f, true
+		}
+
+		for _, tag := range []string{"json", "yaml", "toml"} {
+			if strings.EqualFold(name, strings.Split(f.Tag.Get(tag), ",")[0]) {
+				return f, true
+			}
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// configFieldNames returns the JSON names of the encoded fields of the "typ" struct.
+func configFieldNames(typ reflect.Type) []string {
+	var names []string
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("yaml") == "-" {
+			continue
+		}
+
+		names = append(names, fieldName(typ, f.Name, "json"))
+	}
+
+	return names
+}
+
+// lookupPath returns the value of the "path" of the "v" struct or false if it is not set.
+func lookupPath(v reflect.Value, path fieldPath) (reflect.Value, bool) {
+	for _, elem := range path {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Struct:
+			v = v.FieldByName(elem.(string))
+		case reflect.Map:
+			if v = v.MapIndex(reflect.ValueOf(elem.(string))); !v.IsValid() {
+				return reflect.Value{}, false
+			}
+		default:
+			return reflect.Value{}, false
+		}
+	}
+
+	return v, true
+}
+
+// setPath sets the "value" to the "path" of the "v" struct, the nil pointers and maps are created.
+func setPath(v reflect.Value, path fieldPath, value reflect.Value) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	if len(path) == 0 {
+		v.Set(value)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		setPath(v.FieldByName(path[0].(string)), path[1:], value)
+	case reflect.Map:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+
+		key := reflect.ValueOf(path[0].(string))
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		setPath(elem, path[1:], value)
+		v.SetMapIndex(key, elem)
+	}
+}
+
+// unsetPath resets the struct field or deletes the map key of the "path" of the "v" struct,
+// the pointers and the maps which are left empty are reset too, so their defaults are used.
+func unsetPath(v reflect.Value, path fieldPath) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			if unsetPath(v.Elem(), path); v.Elem().IsZero() {
+				v.Set(reflect.Zero(v.Type()))
+			}
+		}
+	case reflect.Struct:
+		field := v.FieldByName(path[0].(string))
+		if len(path) == 1 {
+			field.Set(reflect.Zero(field.Type()))
+			return
+		}
+		unsetPath(field, path[1:])
+	case reflect.Map:
+		key := reflect.ValueOf(path[0].(string))
+		existing := v.MapIndex(key)
+		if !existing.IsValid() {
+			return
+		}
+
+		if len(path) == 1 {
+			v.SetMapIndex(key, reflect.Value{})
+		} else {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(existing)
+			if unsetPath(elem, path[1:]); elem.IsZero() || (elem.Kind() == reflect.Map && elem.Len() == 0) {
+				v.SetMapIndex(key, reflect.Value{})
+			} else {
+				v.SetMapIndex(key, elem)
+			}
+		}
+
+		if v.Len() == 0 {
+			v.Set(reflect.Zero(v.Type()))
+		}
+	}
+}
+
+// parseConfigValue parses the command-line "s" as a value of the "typ".
+func parseConfigValue(s string, typ reflect.Type) (reflect.Value, error) {
+	v := reflect.New(typ).Elem()
+
+	switch typ.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return v, fmt.Errorf("invalid value: %q, expected true or false", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, typ.Bits())
+		if err != nil {
+			return v, fmt.Errorf("invalid value: %q, expected an integer", s)
+		}
+		v.SetInt(n)
+	case reflect.Interface:
+		// Template answers, e.g. 8080 is an integer and true a boolean.
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(s), &value); err != nil || value == nil {
+			value = s
+		}
+
+		switch value.(type) {
+		case string, bool, int, float64:
+		default:
+			value = s
+		}
+		v.Set(reflect.ValueOf(value))
+	case reflect.Map:
+		return v, fmt.Errorf("cannot set a map, set one of its keys instead")
+	case reflect.Ptr, reflect.Struct:
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		return v, fmt.Errorf("cannot set an object, set one of its fields instead: %s", strings.Join(configFieldNames(typ), ", "))
+	default:
+		return v, fmt.Errorf("cannot set a value of type %s", typ)
+	}
+
+	return v, nil
+}
+
+// configValue returns the new value of a field of the "typ" after the "op" of the "values",
+// "current" is the field's value, if set.
+func configValue(current reflect.Value, typ reflect.Type, op ConfigOp, values []string) (reflect.Value, error) {
+	if typ.Kind() != reflect.Slice {
+		if op == ConfigAppend || op == ConfigRemove {
+			return reflect.Value{}, fmt.Errorf("cannot %s, it is not a list", op)
+		}
+
+		if len(values) != 1 {
+			return reflect.Value{}, fmt.Errorf("expected one value but got %d", len(values))
+		}
+
+		return parseConfigValue(values[0], typ)
+	}
+
+	items := make([]reflect.Value, 0, len(values))
+	for _, s := range values {
+		item, err := parseConfigValue(s, typ.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		items = append(items, item)
+	}
+
+	list := reflect.MakeSlice(typ, 0, len(items))
+	if current.IsValid() && op != ConfigSet {
+		list = reflect.AppendSlice(list, current)
+	}
+
+	switch op {
+	case ConfigRemove:
+		for i, item := range items {
+			found := false
+			for j := 0; j < list.Len(); j++ {
+				if reflect.DeepEqual(list.Index(j).Interface(), item.Interface()) {
+					list = reflect.AppendSlice(list.Slice(0, j), list.Slice(j+1, list.Len()))
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return reflect.Value{}, fmt.Errorf("value %q not found", values[i])
+			}
+		}
+	default:
+		list = reflect.Append(list, items...)
+	}
+
+	return list, nil
+}
+
+// GetConfig returns the value of the project's configuration field of the dotted "path",
+// e.g. livereload.port, see `EditConfig`. The defaults are applied.
+func (p *Project) GetConfig(path string) (interface{}, error) {
+	resolved, _, err := configPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := lookupPath(reflect.ValueOf(p), resolved)
+	if !ok {
+		return nil, fmt.Errorf("%s is not set", path)
+	}
+
+	return v.Interface(), nil
+}
+
+// EditConfig changes the project's configuration field of the dotted "path", e.g. watcher.ignore_dirs,
+// and writes the project file. The fields are matched by their JSON, YAML, TOML or Go names
+// and the "values" are parsed by the field's type. The list fields are set, appended or removed
+// by multiple values, the rest of the fields by a single value. `ConfigUnset` expects no values.
+//
+// The comments of a YAML project file are preserved, the JSON and TOML files are rewritten.
+func (p *Project) EditConfig(path string, op ConfigOp, values ...string) error {
+	resolved, typ, err := configPath(path)
+	if err != nil {
+		return err
+	}
+
+	if op == ConfigUnset {
+		if len(values) > 0 {
+			return fmt.Errorf("%s: unset expects no values", path)
+		}
+	} else if len(values) == 0 {
+		return fmt.Errorf("%s: missing value", path)
+	}
+
+	file := filepath.Join(p.Dest, p.ConfigFile())
+	body, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	raw := &Project{configFile: p.ConfigFile(), Dest: p.Dest}
+	if len(body) > 0 {
+		if _, err = readProjectFile(file, raw); err != nil {
+			return err
+		}
+	}
+
+	var value reflect.Value
+	if op != ConfigUnset {
+		// The lists are appended or removed from their effective values, e.g. the default watcher extensions.
+		current, _ := lookupPath(reflect.ValueOf(p), resolved)
+		if value, err = configValue(current, typ, op, values); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+
+	if op == ConfigUnset {
+		unsetPath(reflect.ValueOf(raw).Elem(), resolved)
+	} else {
+		setPath(reflect.ValueOf(raw), resolved, value)
+	}
+
+	if err = raw.validate(nil); err != nil {
+		return err
+	}
+
+	if projectFileFormat(file) == "yml" {
+		encoded := resolved.encode(reflect.TypeOf(Project{}), "yaml")
+		if body, err = editYAML(body, encoded, op, value, len(values)); err != nil {
+			return fmt.Errorf("%s: %v", p.ConfigFile(), err)
+		}
+
+		if err = os.WriteFile(file, body, 0644); err != nil {
+			return err
+		}
+	} else if err = utils.Export(file, raw); err != nil {
+		return err
+	}
+
+	p.setConfig(raw)
+	return nil
+}
+
+// setConfig sets the configuration fields of the project to the ones of "c" and applies the defaults.
+func (p *Project) setConfig(c *Project) {
+	dst, src := reflect.ValueOf(p).Elem(), reflect.ValueOf(c).Elem()
+	for i := 0; i < dst.NumField(); i++ {
+		if f := dst.Type().Field(i); f.PkgPath == "" && f.Tag.Get("yaml") != "-" {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+
+	p.setDefaults()
+}
+
+// editYAML applies the "op" to the node of the encoded "path" of a YAML document's "body",
+// the rest of the document and its comments are kept. The "value" is the new value of the field,
+// the last "n" of its items are the appended ones.
+func editYAML(body []byte, path fieldPath, op ConfigOp, value reflect.Value, n int) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+
+	if op == ConfigUnset {
+		unsetYAML(doc.Content[0], path)
+		return encodeYAML(&doc)
+	}
+
+	node := doc.Content[0]
+	for i, elem := range path {
+		key := elem.(string)
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s: expected a mapping", path[:i])
+		}
+
+		idx := -1
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == key {
+				idx = j
+				break
+			}
+		}
+
+		last := i == len(path)-1
+		if idx == -1 {
+			child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+			idx = len(node.Content) - 2
+		}
+
+		if !last {
+			if child := node.Content[idx+1]; child.Kind != yaml.MappingNode || child.Tag == "!!null" {
+				node.Content[idx+1] = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", HeadComment: child.HeadComment, LineComment: child.LineComment}
+			}
+			node = node.Content[idx+1]
+			continue
+		}
+
+		newNode := new(yaml.Node)
+		if err := newNode.Encode(value.Interface()); err != nil {
+			return nil, err
+		}
+
+		old := node.Content[idx+1]
+		if old.Kind == yaml.SequenceNode && newNode.Kind == yaml.SequenceNode {
+			switch op {
+			case ConfigAppend:
+				// Keep the existing items and their comments.
+				old.Content = append(old.Content, newNode.Content[len(newNode.Content)-n:]...)
+			case ConfigRemove:
+				// Keep the items of the new value, in order, with their comments.
+				rest, kept := newNode.Content, old.Content[:0]
+				for _, item := range old.Content {
+					if len(rest) > 0 && rest[0].Value == item.Value {
+						kept = append(kept, item)
+						rest = rest[1:]
+					}
+				}
+				old.Content = kept
+			default:
+				old.Content = newNode.Content
+			}
+
+			continue
+		}
+
+		newNode.Style |= old.Style & yaml.FlowStyle
+		newNode.HeadComment, newNode.LineComment, newNode.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		node.Content[idx+1] = newNode
+	}
+
+	return encodeYAML(&doc)
+}
+
+// unsetYAML removes the key of the encoded "path" from the "node" mapping,
+// the mappings which are left empty are removed too.
+func unsetYAML(node *yaml.Node, path fieldPath) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != path[0].(string) {
+			continue
+		}
+
+		if child := node.Content[i+1]; len(path) > 1 {
+			if unsetYAML(child, path[1:]); child.Kind != yaml.MappingNode || len(child.Content) > 0 {
+				return
+			}
+		}
+
+		node.Content = append(node.Content[:i], node.Content[i+2:]...)
+		return
+	}
+}
+
+func encodeYAML(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,133 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProjectEditConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestFile(t, filepath.Join(dir, ProjectFilename), `# The project file.
+Name: app
+Watcher:
+    # Ignored by the watcher.
+    IgnoreDirs:
+        - node_modules # npm
+        - .github
+LiveReload:
+    Port: 35730 # custom
+`)
+
+	p, err := LoadFromDisk(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edits := []struct {
+		path   string
+		op     ConfigOp
+		values []string
+	}{
+		{"livereload.port", ConfigSet, []string{"35731"}},
+		{"watcher.ignore_dirs", ConfigAppend, []string{"dist"}},
+		{"Watcher.IgnoreDirs", ConfigRemove, []string{".github"}},
+		{"watcher.backend", ConfigRemove, []string{".proto"}},
+		{"profiles.dev.env.DEBUG", ConfigSet, []string{"true"}},
+		{"answers.port", ConfigSet, []string{"8080"}},
+	}
+
+	for _, e := range edits {
+		if err = p.EditConfig(e.path, e.op, e.values...); err != nil {
+			t.Fatalf("%s %s: %v", e.op, e.path, err)
+		}
+	}
+
+	expected := `# The project file.
+Name: app
+Watcher:
+    # Ignored by the watcher.
+    IgnoreDirs:
+        - node_modules # npm
+        - dist
+    Backend:
+        - .go
+        - .mod
+        - .yml
+        - .toml
+        - .tml
+        - .ini
+LiveReload:
+    Port: 35731 # custom
+Profiles:
+    dev:
+        Env:
+            DEBUG: "true"
+Answers:
+    port: 8080
+`
+	if got := readTestFile(t, filepath.Join(dir, ProjectFilename)); expected != got {
+		t.Fatalf("expected project file:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	if v, err := p.GetConfig("livereload.port"); err != nil || v != 35731 {
+		t.Fatalf("expected the loaded project to be updated but got: %v (%v)", v, err)
+	}
+
+	if err = p.EditConfig("livereload.port", ConfigUnset); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = p.EditConfig("profiles.dev.env.DEBUG", ConfigUnset); err != nil {
+		t.Fatal(err)
+	}
+
+	if p, err = LoadFromDisk(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := NewLiveReload().Port, p.LiveReload.Port; expected != got {
+		t.Fatalf("expected the default port: %d but got: %d", expected, got)
+	}
+
+	if len(p.Profiles) > 0 {
+		t.Fatalf("expected the empty profiles to be removed but got: %v", p.Profiles)
+	}
+
+	if expected, got := []string{"node_modules", "dist"}, p.Watcher.IgnoreDirs; !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected ignore dirs: %v but got: %v", expected, got)
+	}
+
+	invalid := []struct {
+		path   string
+		op     ConfigOp
+		values []string
+	}{
+		{"livereload.port", ConfigSet, []string{"abc"}},
+		{"livereload.port", ConfigSet, []string{"70000"}},
+		{"livereload.port", ConfigAppend, []string{"1"}},
+		{"watcher.backnd", ConfigSet, []string{".go"}},
+		{"watcher.backend", ConfigSet, []string{"go"}},
+		{"watcher.ignore_dirs", ConfigRemove, []string{"missing"}},
+		{"watcher", ConfigSet, []string{"x"}},
+		{"name", ConfigSet, []string{"a", "b"}},
+	}
+
+	before := readTestFile(t, filepath.Join(dir, ProjectFilename))
+	for _, e := range invalid {
+		if err = p.EditConfig(e.path, e.op, e.values...); err == nil {
+			t.Fatalf("%s %s %v: expected an error", e.op, e.path, e.values)
+		}
+	}
+
+	if got := readTestFile(t, filepath.Join(dir, ProjectFilename)); before != got {
+		t.Fatalf("expected the project file to be kept on errors but got:\n%s", got)
+	}
+}
@@ -0,0 +1,218 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Conflict strategies of the project's files which already exist in the destination directory,
+// see `Project.OnConflict`, `Project.Conflicts` and `Project.ResolveConflict`.
+const (
+	// ConflictSkip keeps the existing file, the project's one is not installed.
+	ConflictSkip = "skip"
+	// ConflictBackup renames the existing file with the `BackupExt` extension
+	// and installs the project's one in its place.
+	ConflictBackup = "backup"
+	// ConflictNew keeps the existing file and installs the project's one
+	// next to it, with the `NewExt` extension.
+	ConflictNew = "new"
+)
+
+// Extensions of the files written on conflicts.
+// A numeric suffix is appended when a file with the same name already exists, e.g. main.go.orig.1.
+const (
+	BackupExt = ".orig"
+	NewExt    = ".new"
+)
+
+// ConflictStrategies is the list of the available conflict strategies.
+var ConflictStrategies = []string{ConflictSkip, ConflictBackup, ConflictNew}
+
+// IsConflictStrategy reports whether "s" is one of the `ConflictStrategies`.
+func IsConflictStrategy(s string) bool {
+	return containsString(ConflictStrategies, s)
+}
+
+// installFile is a staged file or directory to be moved into the destination directory.
+type installFile struct {
+	staged   string // the name under the staging directory.
+	name     string // the name under the destination directory.
+	dir      bool
+	merged   bool   // the directory already exists.
+	conflict string // the conflict strategy, if the file already exists.
+	backup   string // the new name of the existing file, on `ConflictBackup`.
+}
+
+// conflictStrategy returns the strategy of the "name" file which already exists in the destination directory.
+// The most specific of the `Conflicts` patterns which matches the "name" takes precedence,
+// then the `ResolveConflict` and the `OnConflict`. Defaults to `ConflictBackup`.
+func (p *Project) conflictStrategy(name string) (string, error) {
+	patterns := make([]string, 0, len(p.Conflicts))
+	for pattern := range p.Conflicts {
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool { return len(patterns[i]) > len(patterns[j]) })
+
+	strategy := ""
+	for _, pattern := range patterns {
+		if matchPath(pattern, name) {
+			strategy = p.Conflicts[pattern]
+			break
+		}
+	}
+
+	if strategy == "" && p.ResolveConflict != nil {
+		s, err := p.ResolveConflict(name)
+		if err != nil {
+			return "", err
+		}
+		strategy = s
+	}
+
+	if strategy == "" {
+		strategy = p.OnConflict
+	}
+
+	if strategy == "" {
+		return ConflictBackup, nil
+	}
+
+	if !IsConflictStrategy(strategy) {
+		return "", fmt.Errorf("file <%s>: unknown conflict strategy: %s, expected one of: %s", name, strategy, strings.Join(ConflictStrategies, ", "))
+	}
+
+	return strategy, nil
+}
+
+// planInstall compares the staged `Files` with the destination directory and resolves the conflicts
+// of the existing ones. The `Files` are updated to the genuinely new files and directories,
+// so `Unistall` does not remove any user files.
+// The "isDir" reports whether a staged name is a directory.
+func (p *Project) planInstall(isDir func(staged string) (bool, error)) ([]*installFile, error) {
+	var (
+		files    []*installFile
+		newFiles []string
+
+		skipped []string              // staged directories which are not installed.
+		fresh   []string              // destination directories which do not exist before the installation.
+		renamed = map[string]string{} // staged directories installed with a different name.
+		taken   = map[string]struct{}{}
+	)
+
+	for _, staged := range p.Files {
+		dir, err := isDir(staged)
+		if err != nil {
+			return nil, err
+		}
+
+		f := &installFile{staged: staged, name: staged, dir: dir}
+		if parent := parentOf(skipped, staged); parent != "" {
+			f.conflict = ConflictSkip
+			files = append(files, f)
+			continue
+		}
+
+		if parent := parentOf(keys(renamed), staged); parent != "" {
+			f.name = renamed[parent] + strings.TrimPrefix(staged, parent)
+		}
+
+		if parentOf(fresh, f.name) == "" {
+			info, err := os.Lstat(p.path(f.name))
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+
+			if err == nil {
+				if dir && info.IsDir() {
+					// Merge with the existing directory, it is not removed on `Unistall`.
+					f.merged = true
+					files = append(files, f)
+					continue
+				}
+
+				if f.conflict, err = p.conflictStrategy(f.name); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		switch f.conflict {
+		case ConflictSkip:
+			if dir {
+				skipped = append(skipped, staged)
+			}
+			files = append(files, f)
+			continue
+		case ConflictBackup:
+			f.backup = p.freeName(f.name+BackupExt, taken)
+			if !dir {
+				// Overwritten, it is not a new file.
+				files = append(files, f)
+				continue
+			}
+		case ConflictNew:
+			f.name = p.freeName(f.name+NewExt, taken)
+			if dir {
+				renamed[staged] = f.name
+			}
+		}
+
+		if dir {
+			fresh = append(fresh, f.name)
+		}
+
+		files = append(files, f)
+		newFiles = append(newFiles, f.name)
+	}
+
+	p.Files = newFiles
+	return files, nil
+}
+
+// path returns the system path of a slash-separated "name" relative to the destination directory.
+func (p *Project) path(name string) string {
+	return filepath.Join(p.Dest, filepath.FromSlash(name))
+}
+
+// freeName returns the "name", or the "name" with a numeric suffix,
+// which does not exist in the destination directory and it is not already "taken".
+func (p *Project) freeName(name string, taken map[string]struct{}) string {
+	for i := 0; ; i++ {
+		candidate := name
+		if i > 0 {
+			candidate = fmt.Sprintf("%s.%d", name, i)
+		}
+
+		if _, ok := taken[candidate]; ok {
+			continue
+		}
+
+		if _, err := os.Lstat(p.path(candidate)); os.IsNotExist(err) {
+			taken[candidate] = struct{}{}
+			return candidate
+		}
+	}
+}
+
+// parentOf returns the directory of the "dirs" which contains the "name", if any.
+func parentOf(dirs []string, name string) string {
+	for _, dir := range dirs {
+		if strings.HasPrefix(name, dir+"/") {
+			return dir
+		}
+	}
+
+	return ""
+}
+
+func keys(m map[string]string) []string {
+	list := make([]string, 0, len(m))
+	for k := range m {
+		list = append(list, k)
+	}
+
+	return list
+}
@@ -0,0 +1,80 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallConflicts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	archiveFile := filepath.Join(dir, "basic.zip")
+	err = ioutil.WriteFile(archiveFile, newTestZip(t,
+		testZipEntry{Name: "app-main/main.go", Contents: "package main\n", Mode: 0644},
+		testZipEntry{Name: "app-main/README.md", Contents: "template", Mode: 0644},
+		testZipEntry{Name: "app-main/Makefile", Contents: "template", Mode: 0644},
+		testZipEntry{Name: "app-main/web/", Mode: os.ModeDir | 0755},
+		testZipEntry{Name: "app-main/web/index.html", Contents: "template", Mode: 0644},
+		testZipEntry{Name: "app-main/web/app.js", Contents: "template", Mode: 0644},
+		testZipEntry{Name: "app-main/docs/", Mode: os.ModeDir | 0755},
+		testZipEntry{Name: "app-main/docs/index.md", Contents: "template", Mode: 0644},
+	), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(dir, "app")
+	writeTestFile(t, filepath.Join(dest, "main.go"), "user")
+	writeTestFile(t, filepath.Join(dest, "README.md"), "user")
+	writeTestFile(t, filepath.Join(dest, "Makefile"), "user")
+	writeTestFile(t, filepath.Join(dest, "web", "index.html"), "user")
+	writeTestFile(t, filepath.Join(dest, "docs"), "user") // a file in place of a directory.
+
+	p := &Project{
+		Repo:       "file://" + filepath.ToSlash(archiveFile),
+		Dest:       dest,
+		OnConflict: ConflictSkip,
+		Conflicts:  map[string]string{"*.md": ConflictNew, "main.go": ConflictBackup, "docs": ConflictNew},
+		NoVerify:   true,
+	}
+	if err = p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]string{
+		"main.go":             "package main\n",
+		"main.go" + BackupExt: "user",
+		"README.md":           "user",
+		"README.md.new":       "template",
+		"Makefile":            "user",
+		"web/index.html":      "user",
+		"web/app.js":          "template",
+		"docs":                "user",
+		"docs.new/index.md":   "template",
+	} {
+		if got := readTestFile(t, filepath.Join(dest, filepath.FromSlash(name))); expected != got {
+			t.Fatalf("%s: expected contents: %q but got: %q", name, expected, got)
+		}
+	}
+
+	if expected, got := "go.mod README.md.new web/app.js docs.new docs.new/index.md", strings.Join(p.Files, " "); expected != got {
+		t.Fatalf("expected files: %s but got: %s", expected, got)
+	}
+
+	if err = p.Unistall(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"main.go", "main.go" + BackupExt, "README.md", "Makefile", "web/index.html", "docs"} {
+		if _, err = os.Stat(filepath.Join(dest, filepath.FromSlash(name))); err != nil {
+			t.Fatalf("expected %s to be kept after unistall: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of the unchanged lines around the changes of a unified diff.
+const diffContext = 3
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'.
+	a, b int  // the line indexes of the old and the new text.
+}
+
+// unifiedDiff returns the unified diff of the "name" file's "oldContents" and "newContents",
+// or an empty string if they are equal.
+func unifiedDiff(name string, oldContents, newContents []byte) string {
+	var (
+		a       = splitLines(string(oldContents))
+		b       = splitLines(string(newContents))
+		matches = matchLines(a, b)
+		ops     []diffOp
+	)
+
+	for i, j := 0, 0; i < len(a) || j < len(b); {
+		switch {
+		case i < len(a) && matches[i] == -1:
+			ops = append(ops, diffOp{'-', i, j})
+			i++
+		case i < len(a) && matches[i] == j:
+			ops = append(ops, diffOp{' ', i, j})
+			i++
+			j++
+		default:
+			ops = append(ops, diffOp{'+', i, j})
+			j++
+		}
+	}
+
+	out := new(strings.Builder)
+	for start := 0; start < len(ops); {
+		// Find the next change.
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk while the changes are close enough.
+		end := start
+		for k := start; k < len(ops); k++ {
+			if ops[k].kind != ' ' {
+				end = k + 1
+			} else if k-end >= 2*diffContext {
+				break
+			}
+		}
+
+		first := start - diffContext
+		if first < 0 {
+			first = 0
+		}
+		last := end + diffContext
+		if last > len(ops) {
+			last = len(ops)
+		}
+
+		if out.Len() == 0 {
+			fmt.Fprintf(out, "--- a/%s\n+++ b/%s\n", name, name)
+		}
+
+		var aLen, bLen int
+		for _, op := range ops[first:last] {
+			if op.kind != '+' {
+				aLen++
+			}
+			if op.kind != '-' {
+				bLen++
+			}
+		}
+
+		aStart, bStart := ops[first].a, ops[first].b
+		if aLen > 0 {
+			aStart++
+		}
+		if bLen > 0 {
+			bStart++
+		}
+
+		fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen)
+		for _, op := range ops[first:last] {
+			line := ""
+			if op.kind == '+' {
+				line = b[op.b]
+			} else {
+				line = a[op.a]
+			}
+
+			out.WriteByte(op.kind)
+			out.WriteString(line)
+			if !strings.HasSuffix(line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		start = last
+	}
+
+	return out.String()
+}
@@ -0,0 +1,75 @@
+package project
+
+import (
+	"github.com/kataras/iris-cli/utils"
+)
+
+// DryRunFile describes a file which would be installed.
+type DryRunFile struct {
+	Name string
+	Dir  bool
+	// Conflict is the conflict strategy when the file already exists in the destination directory.
+	Conflict string
+	// Backup is the new name of the existing file on `ConflictBackup`.
+	Backup string
+	// Diff is the unified diff of the module path and replacements rewrites, if any.
+	Diff string
+}
+
+// DryRunResult is the result of `Project.DryRun`.
+type DryRunResult struct {
+	Dest            string
+	Files           []*DryRunFile
+	ProjectFilename string // the project file which would be written, see `Project.ConfigFile`.
+	ProjectFile     []byte // the contents of the project file which would be written.
+}
+
+// DryRun downloads, or reads from the cache, and extracts the project in memory
+// and reports the files which would be created, overwritten or skipped by `Install`,
+// the rewrites of their contents and the project file.
+// Nothing is written to the disk, the downloaded archive is not stored in the cache either.
+func (p *Project) DryRun() (*DryRunResult, error) {
+	p.staged = make(map[string]*stagedFile)
+	defer func() { p.staged = nil }()
+
+	b, format, err := p.download()
+	if err != nil {
+		return nil, err
+	}
+
+	p.Dest = utils.Dest(p.Dest)
+	if _, err = p.stage(b, format, p.Dest); err != nil {
+		return nil, err
+	}
+
+	files, err := p.planInstall(func(staged string) (bool, error) {
+		f, ok := p.staged[staged]
+		return ok && f.Mode.IsDir(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DryRunResult{Dest: p.Dest}
+	for _, f := range files {
+		staged, ok := p.staged[f.staged]
+		if !ok || f.merged {
+			continue
+		}
+
+		file := &DryRunFile{Name: f.name, Dir: f.dir, Conflict: f.conflict, Backup: f.backup}
+		if staged.Original != nil && f.conflict != ConflictSkip {
+			file.Diff = unifiedDiff(f.name, staged.Original, staged.Contents)
+		}
+
+		result.Files = append(result.Files, file)
+	}
+
+	p.setDefaults()
+	result.ProjectFilename = p.ConfigFile()
+	if result.ProjectFile, err = utils.Marshal(result.ProjectFilename, p); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
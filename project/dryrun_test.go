@@ -0,0 +1,90 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	archiveFile := filepath.Join(dir, "basic.zip")
+	err = ioutil.WriteFile(archiveFile, newTestZip(t,
+		testZipEntry{Name: "app-main/main.go", Contents: "package main\n\nimport _ \"github.com/author/app/routes\"\n", Mode: 0644},
+		testZipEntry{Name: "app-main/routes/routes.go", Contents: "package routes\n", Mode: 0644},
+	), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(dir, "app")
+	writeTestFile(t, filepath.Join(dest, "main.go"), "package main\n")
+
+	p := &Project{Repo: "file://" + filepath.ToSlash(archiveFile), Dest: dest, Module: "github.com/me/app"}
+	result, err := p.DryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{"go.mod": "", "main.go": ConflictBackup, "routes/routes.go": ""} // name: conflict.
+	if len(result.Files) != len(expected) {
+		t.Fatalf("expected %d files but got %d", len(expected), len(result.Files))
+	}
+
+	for _, f := range result.Files {
+		if conflict, ok := expected[f.Name]; !ok || conflict != f.Conflict {
+			t.Fatalf("unexpected file: %s (conflict: %q)", f.Name, f.Conflict)
+		}
+
+		if f.Name == "main.go" {
+			expectedDiff := `--- a/main.go
++++ b/main.go
+@@ -1,3 +1,3 @@
+ package main
+ 
+-import _ "github.com/author/app/routes"
++import _ "github.com/me/app/routes"
+`
+			if f.Diff != expectedDiff {
+				t.Fatalf("expected diff:\n%s\nbut got:\n%s", expectedDiff, f.Diff)
+			}
+		}
+	}
+
+	if !strings.Contains(string(result.ProjectFile), "Module: github.com/me/app\n") {
+		t.Fatalf("expected project file to contain the new module but got:\n%s", result.ProjectFile)
+	}
+
+	if expected, got := ProjectFilename, result.ProjectFilename; expected != got {
+		t.Fatalf("expected project filename: %s but got: %s", expected, got)
+	}
+
+	// The project file is rendered in the format of the project's configuration file.
+	p = &Project{Repo: "file://" + filepath.ToSlash(archiveFile), Dest: dest, Module: "github.com/me/app", configFile: "iris.toml"}
+	if result, err = p.DryRun(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "iris.toml", result.ProjectFilename; expected != got {
+		t.Fatalf("expected project filename: %s but got: %s", expected, got)
+	}
+
+	if !strings.Contains(string(result.ProjectFile), "Module = 'github.com/me/app'\n") {
+		t.Fatalf("expected a toml project file with the new module but got:\n%s", result.ProjectFile)
+	}
+
+	if expected, got := "package main\n", readTestFile(t, filepath.Join(dest, "main.go")); expected != got {
+		t.Fatalf("expected main.go to be untouched but got: %q", got)
+	}
+
+	if _, err = os.Stat(filepath.Join(dest, "routes")); !os.IsNotExist(err) {
+		t.Fatalf("expected routes directory to not be created")
+	}
+}
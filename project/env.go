@@ -0,0 +1,154 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// DefaultEnvFile is the environment file which is loaded on `Run`, if exists, before the profile's ones.
+const DefaultEnvFile = ".env"
+
+// Profile is a named set of environment variables, environment files and build tags
+// which is applied on `Run`, see `Project.Profiles`.
+//
+// Example:
+//
+//	Profiles:
+//	  dev:
+//	    Env:
+//	      DEBUG: "true"
+//	  staging:
+//	    EnvFiles: [.env.staging]
+//	    Env:
+//	      PORT: "8081"
+//	    Tags: [staging]
+type Profile struct {
+	// Env are the environment variables, they override the ones of the `EnvFiles`.
+	Env map[string]string `json:"env,omitempty" yaml:"Env,omitempty" toml:"Env"`
+	// EnvFiles are the environment files, relative to the project's directory,
+	// which are loaded after the `DefaultEnvFile`. Later files override the earlier ones.
+	EnvFiles []string `json:"env_files,omitempty" yaml:"EnvFiles,omitempty" toml:"EnvFiles"`
+	// Tags are the Go build tags of the project's executable.
+	Tags []string `json:"tags,omitempty" yaml:"Tags,omitempty" toml:"Tags"`
+}
+
+// profile returns the selected `Profile` or nil.
+func (p *Project) profile() (*Profile, error) {
+	if p.Profile == "" {
+		return nil, nil
+	}
+
+	profile, ok := p.Profiles[p.Profile]
+	if !ok || profile == nil {
+		names := make([]string, 0, len(p.Profiles))
+		for name := range p.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		if len(names) == 0 {
+			return nil, fmt.Errorf("profile <%s> does not exist, the project has no profiles", p.Profile)
+		}
+
+		return nil, fmt.Errorf("profile <%s> does not exist, expected one of: %s", p.Profile, strings.Join(names, ", "))
+	}
+
+	return profile, nil
+}
+
+// envFiles returns the environment files of the project's directory which are loaded on `Run`.
+func (p *Project) envFiles() ([]string, error) {
+	files := []string{DefaultEnvFile}
+
+	profile, err := p.profile()
+	if err != nil {
+		return nil, err
+	}
+
+	if profile != nil {
+		for _, name := range profile.EnvFiles {
+			if name = filepath.ToSlash(filepath.Clean(name)); name != DefaultEnvFile {
+				files = append(files, name)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// isEnvFile reports whether the slash-separated "name", relative to the project's directory,
+// is one of the environment files, their changes restart the backend.
+func (p *Project) isEnvFile(name string) bool {
+	files, err := p.envFiles()
+	if err != nil {
+		return false
+	}
+
+	return containsString(files, name)
+}
+
+// loadEnv resolves the environment of the commands which are executed on `Run`:
+// the CLI's environment, overridden by the environment files and the selected profile's variables.
+// The `DefaultEnvFile` is optional, a missing profile's environment file is an error.
+func (p *Project) loadEnv() error {
+	files, err := p.envFiles()
+	if err != nil {
+		return err
+	}
+
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if i := strings.IndexByte(kv, '='); i > 0 {
+			env[kv[:i]] = kv[i+1:]
+		}
+	}
+
+	for i, name := range files {
+		f, err := os.Open(filepath.Join(p.Dest, filepath.FromSlash(name)))
+		if err != nil {
+			if i == 0 && os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("env file: %w", err)
+		}
+
+		vars, err := godotenv.Parse(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("env file: %s: %v", name, err)
+		}
+
+		for k, v := range vars {
+			env[k] = v
+		}
+	}
+
+	profile, _ := p.profile()
+	if profile != nil {
+		for k, v := range profile.Env {
+			env[k] = v
+		}
+	}
+
+	p.env = make([]string, 0, len(env))
+	for k, v := range env {
+		p.env = append(p.env, k+"="+v)
+	}
+	sort.Strings(p.env)
+
+	return nil
+}
+
+// buildTags returns the Go build tags of the selected profile.
+func (p *Project) buildTags() []string {
+	if profile, _ := p.profile(); profile != nil {
+		return profile.Tags
+	}
+
+	return nil
+}
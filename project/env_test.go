@@ -0,0 +1,100 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProjectLoadEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestFile(t, filepath.Join(dir, ".env"), "APP_NAME=app\nAPP_PORT=8080\nAPP_DEBUG=true\n")
+	writeTestFile(t, filepath.Join(dir, ".env.staging"), "# staging\nAPP_PORT=8081\nAPP_DB=\"postgres://staging\"\n")
+
+	os.Setenv("APP_CLI", "cli")
+	os.Setenv("APP_DEBUG", "cli")
+	defer os.Unsetenv("APP_CLI")
+	defer os.Unsetenv("APP_DEBUG")
+
+	p := &Project{
+		Dest: dir,
+		Profiles: map[string]*Profile{
+			"staging": {
+				EnvFiles: []string{".env.staging"},
+				Env:      map[string]string{"APP_DEBUG": "false"},
+				Tags:     []string{"staging", "postgres"},
+			},
+			"broken": {
+				EnvFiles: []string{".env.missing"},
+			},
+		},
+	}
+
+	lookup := func(key string) string {
+		t.Helper()
+
+		prefix := key + "="
+		for _, kv := range p.env {
+			if strings.HasPrefix(kv, prefix) {
+				return strings.TrimPrefix(kv, prefix)
+			}
+		}
+
+		t.Fatalf("expected environment variable %s", key)
+		return ""
+	}
+
+	// No profile, the .env file only.
+	if err = p.loadEnv(); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, expected := range map[string]string{"APP_NAME": "app", "APP_PORT": "8080", "APP_DEBUG": "true", "APP_CLI": "cli"} {
+		if got := lookup(key); expected != got {
+			t.Fatalf("expected %s=%s but got: %s", key, expected, got)
+		}
+	}
+
+	if tags := p.buildTags(); len(tags) > 0 {
+		t.Fatalf("expected no build tags but got: %v", tags)
+	}
+
+	p.Profile = "staging"
+	if err = p.loadEnv(); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, expected := range map[string]string{"APP_NAME": "app", "APP_PORT": "8081", "APP_DEBUG": "false", "APP_DB": "postgres://staging"} {
+		if got := lookup(key); expected != got {
+			t.Fatalf("staging: expected %s=%s but got: %s", key, expected, got)
+		}
+	}
+
+	if expected, got := []string{"staging", "postgres"}, p.buildTags(); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected build tags: %v but got: %v", expected, got)
+	}
+
+	for name, expected := range map[string]bool{".env": true, ".env.staging": true, ".env.production": false, "main.go": false} {
+		if got := p.isEnvFile(name); expected != got {
+			t.Fatalf("expected %s to be an env file: %v but got: %v", name, expected, got)
+		}
+	}
+
+	p.Profile = "broken"
+	if err = p.loadEnv(); err == nil {
+		t.Fatalf("expected an error on missing profile env file")
+	}
+
+	p.Profile = "production"
+	if err = p.loadEnv(); err == nil {
+		t.Fatalf("expected an error on unknown profile")
+	}
+}
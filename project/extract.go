@@ -0,0 +1,333 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Default extraction limits, see `Project.MaxSize` and `Project.MaxFiles`.
+const (
+	DefaultMaxSize  int64 = 512 << 20 // 512 MiB.
+	DefaultMaxFiles       = 20000
+)
+
+var (
+	// ErrIllegalPath is reported when an archive entry would be written outside of the destination directory.
+	ErrIllegalPath = errors.New("path escapes the destination directory")
+	// ErrSymlink is reported when an archive entry is a hard link or a symbolic link which points outside of the destination directory.
+	ErrSymlink = errors.New("link points outside of the destination directory")
+	// ErrMaxSize is reported when the total uncompressed size of an archive exceeds the `Project.MaxSize`.
+	ErrMaxSize = errors.New("uncompressed size exceeds the limit")
+	// ErrMaxFiles is reported when the number of an archive's entries exceeds the `Project.MaxFiles`.
+	ErrMaxFiles = errors.New("number of files exceeds the limit")
+	// ErrUnsupportedEntry is reported when an archive entry is not a regular file, a directory or a symbolic link.
+	ErrUnsupportedEntry = errors.New("unsupported entry type")
+)
+
+// ExtractError is the type of error returned from `Project.Install`
+// when an archive entry violates the extraction rules.
+// See `IsExtractError` too.
+type ExtractError struct {
+	// Name is the archive entry, relative to the archive's root folder.
+	Name string
+	// Err is one of the ErrIllegalPath, ErrSymlink, ErrMaxSize, ErrMaxFiles and ErrUnsupportedEntry.
+	Err error
+}
+
+func (e *ExtractError) Error() string {
+	return fmt.Sprintf("unsafe archive entry <%s>: %v", e.Name, e.Err)
+}
+
+// Unwrap returns the underline error.
+func (e *ExtractError) Unwrap() error {
+	return e.Err
+}
+
+// IsExtractError reports whether an "err" is caused by an unsafe archive entry.
+func IsExtractError(err error) (*ExtractError, bool) {
+	var extractErr *ExtractError
+	if errors.As(err, &extractErr) {
+		return extractErr, true
+	}
+
+	return nil, false
+}
+
+// extractor writes archive entries under a destination directory,
+// it guards against path traversal, links outside of the destination and zip bombs.
+type extractor struct {
+	dest     string
+	maxSize  int64
+	maxFiles int
+
+	size  int64
+	count int
+
+	// staged, if not nil, keeps the extracted entries in memory
+	// instead of writing them to the destination, see `Project.DryRun`.
+	staged map[string]*stagedFile
+
+	// realDest is the destination with its symbolic links resolved, see `contains`.
+	realDest string
+}
+
+// stagedFile is an in-memory extracted entry.
+type stagedFile struct {
+	Mode     os.FileMode
+	Contents []byte
+	Link     string
+	Original []byte // the contents before the rewrites, if any.
+}
+
+func newExtractor(dest string, maxSize int64, maxFiles int) *extractor {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	if maxFiles <= 0 {
+		maxFiles = DefaultMaxFiles
+	}
+
+	return &extractor{dest: filepath.Clean(dest), maxSize: maxSize, maxFiles: maxFiles}
+}
+
+// path returns the system path of a slash-separated "name" which must be contained by the destination.
+// See https://snyk.io/research/zip-slip-vulnerability#go.
+func (e *extractor) path(name string) (string, error) {
+	if name == "" || path.IsAbs(name) || strings.Contains(name, `\`) || filepath.VolumeName(name) != "" {
+		return "", &ExtractError{Name: name, Err: ErrIllegalPath}
+	}
+
+	fpath := filepath.Join(e.dest, filepath.FromSlash(name))
+	if !strings.HasPrefix(fpath, e.dest+string(os.PathSeparator)) {
+		return "", &ExtractError{Name: name, Err: ErrIllegalPath}
+	}
+
+	return fpath, nil
+}
+
+// realPath returns the "fpath" with the symbolic links of its existing part resolved,
+// the rest of it does not exist yet.
+func realPath(fpath string) (string, error) {
+	existing, rest := fpath, ""
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(resolved, rest), nil
+}
+
+// contains reports whether the "fpath", after following the symbolic links which are already written,
+// is the destination or it is contained by the destination.
+// It guards against links of previous entries, e.g. "a/l -> .." and then "a/l/x -> ../..".
+func (e *extractor) contains(fpath string) (bool, error) {
+	if e.realDest == "" {
+		realDest, err := realPath(e.dest)
+		if err != nil {
+			return false, err
+		}
+		e.realDest = realDest
+	}
+
+	resolved, err := realPath(fpath)
+	if err != nil {
+		if os.IsNotExist(err) { // a dangling link.
+			return false, nil
+		}
+		return false, err
+	}
+
+	return resolved == e.realDest || strings.HasPrefix(resolved, e.realDest+string(os.PathSeparator)), nil
+}
+
+// checkParent returns an error if the parent directory of the "name" entry, its system path is "fpath",
+// resolves outside of the destination.
+func (e *extractor) checkParent(name, fpath string) error {
+	ok, err := e.contains(filepath.Dir(fpath))
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return &ExtractError{Name: name, Err: ErrIllegalPath}
+	}
+
+	return nil
+}
+
+// add counts an entry against the max files limit.
+func (e *extractor) add(name string) error {
+	e.count++
+	if e.count > e.maxFiles {
+		return &ExtractError{Name: name, Err: ErrMaxFiles}
+	}
+
+	return nil
+}
+
+// read reads the whole contents of an entry, counting them against the max size limit.
+func (e *extractor) read(name string, r io.Reader) ([]byte, error) {
+	remaining := e.maxSize - e.size
+	b, err := ioutil.ReadAll(io.LimitReader(r, remaining+1))
+	if err != nil {
+		return nil, err
+	}
+
+	e.size += int64(len(b))
+	if e.size > e.maxSize {
+		return nil, &ExtractError{Name: name, Err: ErrMaxSize}
+	}
+
+	return b, nil
+}
+
+// mkdir creates the "name" directory and any of its parents.
+func (e *extractor) mkdir(name string, mode os.FileMode) error {
+	fpath, err := e.path(name)
+	if err != nil {
+		return err
+	}
+
+	if e.staged != nil {
+		e.staged[name] = &stagedFile{Mode: os.ModeDir | dirPerm(mode)}
+		return nil
+	}
+
+	if ok, err := e.contains(fpath); err != nil {
+		return err
+	} else if !ok {
+		return &ExtractError{Name: name, Err: ErrIllegalPath}
+	}
+
+	if err = os.MkdirAll(fpath, dirPerm(mode)); err != nil {
+		return err
+	}
+
+	return os.Chmod(fpath, dirPerm(mode))
+}
+
+// writeFile writes the "contents" to the "name" file and sets its permissions.
+func (e *extractor) writeFile(name string, contents []byte, mode os.FileMode) error {
+	fpath, err := e.path(name)
+	if err != nil {
+		return err
+	}
+
+	if e.staged != nil {
+		e.staged[name] = &stagedFile{Mode: filePerm(mode), Contents: contents}
+		return nil
+	}
+
+	// Do not follow the links of the parent directories outside of the destination.
+	if err = e.checkParent(name, fpath); err != nil {
+		return err
+	}
+
+	// Archives may not contain entries for the parent directories.
+	if err = os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+		return err
+	}
+
+	// Do not follow an existing symbolic link.
+	if info, statErr := os.Lstat(fpath); statErr == nil && info.Mode()&os.ModeSymlink != 0 {
+		if err = os.Remove(fpath); err != nil {
+			return err
+		}
+	}
+
+	perm := filePerm(mode)
+	if err = ioutil.WriteFile(fpath, contents, perm); err != nil {
+		return err
+	}
+
+	// The permissions of an existing file are not modified by WriteFile.
+	return os.Chmod(fpath, perm)
+}
+
+// symlink creates the "name" symbolic link, the "target" must be relative and contained by the destination.
+func (e *extractor) symlink(name, target string) error {
+	fpath, err := e.path(name)
+	if err != nil {
+		return err
+	}
+
+	if target == "" || path.IsAbs(target) || strings.Contains(target, `\`) || filepath.VolumeName(target) != "" {
+		return &ExtractError{Name: name, Err: ErrSymlink}
+	}
+
+	if _, err = e.path(path.Join(path.Dir(name), target)); err != nil {
+		return &ExtractError{Name: name, Err: ErrSymlink}
+	}
+
+	if e.staged != nil {
+		e.staged[name] = &stagedFile{Mode: os.ModeSymlink | 0777, Link: target}
+		return nil
+	}
+
+	if err = e.checkParent(name, fpath); err != nil {
+		return err
+	}
+
+	// The target is relative to the real parent directory, which may be a link of a previous entry.
+	parent, err := realPath(filepath.Dir(fpath))
+	if err != nil {
+		return err
+	}
+
+	if ok, err := e.contains(filepath.Join(parent, filepath.FromSlash(target))); err != nil {
+		return err
+	} else if !ok {
+		return &ExtractError{Name: name, Err: ErrSymlink}
+	}
+
+	if err = os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+		return err
+	}
+
+	if _, statErr := os.Lstat(fpath); statErr == nil {
+		if err = os.Remove(fpath); err != nil {
+			return err
+		}
+	}
+
+	return os.Symlink(filepath.FromSlash(target), fpath)
+}
+
+func dirPerm(mode os.FileMode) os.FileMode {
+	if perm := mode.Perm(); perm != 0 {
+		return perm | 0700 // the owner should always be able to write its contents.
+	}
+
+	return 0755
+}
+
+func filePerm(mode os.FileMode) os.FileMode {
+	if perm := mode.Perm(); perm != 0 {
+		return perm | 0600
+	}
+
+	return 0644
+}
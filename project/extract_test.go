@@ -0,0 +1,290 @@
+package project
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+type testZipEntry struct {
+	Name     string
+	Contents string
+	Mode     os.FileMode
+	Hardlink string // the target of a tar hard link, see `newTestTarGzEntries`.
+}
+
+func newTestZip(t *testing.T, entries ...testZipEntry) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+
+	for _, entry := range append([]testZipEntry{
+		{Name: "app-main/", Mode: os.ModeDir | 0755},
+		{Name: "app-main/go.mod", Contents: "module github.com/author/app\n", Mode: 0644},
+	}, entries...) {
+		header := &zip.FileHeader{Name: entry.Name, Method: zip.Deflate}
+		header.SetMode(entry.Mode)
+
+		fw, err := w.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = fw.Write([]byte(entry.Contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// newTestTarGzEntries is like `newTestZip` but it writes a tar.gz archive, the entries are kept in order.
+func newTestTarGzEntries(t *testing.T, entries ...testZipEntry) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+
+	for _, entry := range append([]testZipEntry{
+		{Name: "app-main/", Mode: os.ModeDir | 0755},
+		{Name: "app-main/go.mod", Contents: "module github.com/author/app\n", Mode: 0644},
+	}, entries...) {
+		h := &tar.Header{Name: entry.Name, Mode: int64(entry.Mode.Perm()), Typeflag: tar.TypeReg, Size: int64(len(entry.Contents))}
+		switch {
+		case entry.Hardlink != "":
+			h.Typeflag, h.Size, h.Linkname = tar.TypeLink, 0, entry.Hardlink
+		case entry.Mode.IsDir():
+			h.Typeflag, h.Size = tar.TypeDir, 0
+		case entry.Mode&os.ModeSymlink != 0:
+			h.Typeflag, h.Size, h.Linkname = tar.TypeSymlink, 0, entry.Contents
+		}
+
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+
+		if h.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(entry.Contents)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestUnzipExtractErrors(t *testing.T) {
+	tests := []struct {
+		entry    testZipEntry
+		maxSize  int64
+		maxFiles int
+		expected error
+	}{
+		{entry: testZipEntry{Name: "app-main/../../evil.go", Contents: "package evil", Mode: 0644}, expected: ErrIllegalPath},
+		{entry: testZipEntry{Name: "app-main/link", Contents: "../../etc/passwd", Mode: os.ModeSymlink | 0777}, expected: ErrSymlink},
+		{entry: testZipEntry{Name: "app-main/link", Contents: "/etc/passwd", Mode: os.ModeSymlink | 0777}, expected: ErrSymlink},
+		{entry: testZipEntry{Name: "app-main/big.txt", Contents: string(make([]byte, 1024)), Mode: 0644}, maxSize: 512, expected: ErrMaxSize},
+		{entry: testZipEntry{Name: "app-main/main.go", Contents: "package main", Mode: 0644}, maxFiles: 1, expected: ErrMaxFiles},
+	}
+
+	for i, tt := range tests {
+		dir, err := ioutil.TempDir("", "iris-cli-test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		p := &Project{Dest: filepath.Join(dir, "app"), MaxSize: tt.maxSize, MaxFiles: tt.maxFiles}
+		_, err = p.unzip(newTestZip(t, tt.entry), ArchiveZip, p.Dest)
+		extractErr, ok := IsExtractError(err)
+		if !ok {
+			t.Fatalf("[%d] expected an extract error but got: %v", i, err)
+		}
+
+		if extractErr.Err != tt.expected {
+			t.Fatalf("[%d] expected error: %v but got: %v", i, tt.expected, extractErr.Err)
+		}
+	}
+}
+
+func TestUnzipFileModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on windows")
+	}
+
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := &Project{Dest: filepath.Join(dir, "app")}
+	_, err = p.unzip(newTestZip(t,
+		testZipEntry{Name: "app-main/run.sh", Contents: "#!/bin/sh", Mode: 0755},
+		testZipEntry{Name: "app-main/scripts", Contents: "run.sh", Mode: os.ModeSymlink | 0777},
+	), ArchiveZip, p.Dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(filepath.Join(p.Dest, "run.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := os.FileMode(0755), info.Mode().Perm(); expected != got {
+		t.Fatalf("expected file mode: %s but got: %s", expected, got)
+	}
+
+	target, err := os.Readlink(filepath.Join(p.Dest, "scripts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "run.sh", target; expected != got {
+		t.Fatalf("expected symbolic link target: %s but got: %s", expected, got)
+	}
+}
+
+func TestUnzipChainedSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require privileges on windows")
+	}
+
+	// Each link is inside the destination by its name,
+	// but "m" resolves through the "l" link which is already written, see `extractor.contains`.
+	entries := []testZipEntry{
+		{Name: "app-main/x/y/z/l", Contents: "../..", Mode: os.ModeSymlink | 0777},
+		{Name: "app-main/x/y/z/l/m", Contents: "../../..", Mode: os.ModeSymlink | 0777},
+		{Name: "app-main/x/y/z/l/m/evil.go", Contents: "package evil", Mode: 0644},
+	}
+
+	archives := []struct {
+		format string
+		body   []byte
+	}{
+		{ArchiveZip, newTestZip(t, entries...)},
+		{ArchiveTarGz, newTestTarGzEntries(t, entries...)},
+	}
+
+	for _, archive := range archives {
+		dir, err := ioutil.TempDir("", "iris-cli-test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		p := &Project{Dest: filepath.Join(dir, "a", "b", "app")}
+		_, err = p.unzip(archive.body, archive.format, p.Dest)
+		extractErr, ok := IsExtractError(err)
+		if !ok {
+			t.Fatalf("%s: expected an extract error but got: %v", archive.format, err)
+		}
+
+		if extractErr.Err != ErrSymlink {
+			t.Fatalf("%s: expected error: %v but got: %v", archive.format, ErrSymlink, extractErr.Err)
+		}
+
+		for _, name := range []string{"evil.go", filepath.Join("a", "evil.go")} {
+			if _, err = os.Lstat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+				t.Fatalf("%s: expected %s to not be written outside of the destination", archive.format, name)
+			}
+		}
+	}
+
+	// A symbolic link which exists in the destination before the extraction.
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := &Project{Dest: filepath.Join(dir, "app")}
+	outside := filepath.Join(dir, "outside")
+	if err = os.MkdirAll(outside, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.MkdirAll(p.Dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Symlink(outside, filepath.Join(p.Dest, "out")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, archive := range []struct {
+		format string
+		body   []byte
+	}{
+		{ArchiveZip, newTestZip(t, testZipEntry{Name: "app-main/out/evil.go", Contents: "package evil", Mode: 0644})},
+		{ArchiveTarGz, newTestTarGzEntries(t, testZipEntry{Name: "app-main/out/evil.go", Contents: "package evil", Mode: 0644})},
+	} {
+		_, err = p.unzip(archive.body, archive.format, p.Dest)
+		extractErr, ok := IsExtractError(err)
+		if !ok {
+			t.Fatalf("%s: expected an extract error but got: %v", archive.format, err)
+		}
+
+		if extractErr.Err != ErrIllegalPath {
+			t.Fatalf("%s: expected error: %v but got: %v", archive.format, ErrIllegalPath, extractErr.Err)
+		}
+
+		if _, err = os.Lstat(filepath.Join(outside, "evil.go")); !os.IsNotExist(err) {
+			t.Fatalf("%s: expected evil.go to not be written through the link", archive.format)
+		}
+	}
+}
+
+func TestUnzipTarHardlinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := &Project{Dest: filepath.Join(dir, "app")}
+	_, err = p.unzip(newTestTarGzEntries(t,
+		testZipEntry{Name: "app-main/main.go", Contents: "package main\n", Mode: 0644},
+		testZipEntry{Name: "app-main/cmd/main.go", Hardlink: "app-main/main.go"},
+	), ArchiveTarGz, p.Dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "package main\n", readTestFile(t, filepath.Join(p.Dest, "cmd", "main.go")); expected != got {
+		t.Fatalf("expected the hard link to be extracted as a copy: %q but got: %q", expected, got)
+	}
+
+	for _, target := range []string{"/etc/passwd", "../../etc/passwd", "app-main/missing.go"} {
+		p := &Project{Dest: filepath.Join(dir, "evil")}
+		_, err = p.unzip(newTestTarGzEntries(t,
+			testZipEntry{Name: "app-main/evil", Hardlink: target},
+		), ArchiveTarGz, p.Dest)
+		extractErr, ok := IsExtractError(err)
+		if !ok {
+			t.Fatalf("%s: expected an extract error but got: %v", target, err)
+		}
+
+		if extractErr.Err != ErrSymlink {
+			t.Fatalf("%s: expected error: %v but got: %v", target, ErrSymlink, extractErr.Err)
+		}
+	}
+}
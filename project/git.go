@@ -0,0 +1,365 @@
+package project
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/kataras/iris-cli/utils"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/filemode"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/client"
+	githttp "gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	gitssh "gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+)
+
+// GitTokenEnv is the environment variable which is used
+// as the HTTPS authentication token when `GitSource.Token` is empty.
+const GitTokenEnv = "IRIS_CLI_GIT_TOKEN"
+
+// GitSource is a `Source` which clones a git repository at a branch, a tag or an exact commit,
+// instead of downloading a release archive. Supported forms of a `Project.Repo`:
+//
+//	git+https://github.com/owner/repo.git
+//	git+ssh://git@github.com/owner/repo.git
+//	git@github.com:owner/repo.git
+//	git+file:///path/to/repo
+//
+// HTTPS repositories are authenticated through the `Token` field
+// and SSH repositories through the running SSH agent.
+type GitSource struct {
+	// Token is the HTTPS personal access token (or password),
+	// defaults to the IRIS_CLI_GIT_TOKEN environment variable.
+	Token string
+	// Username is the HTTPS username, most providers accept any non-empty value with a token.
+	// Defaults to "iris-cli".
+	Username string
+}
+
+// DefaultGitSource is the `GitSource` registered for the "git+https", "git+http", "git+ssh" and "git+file" schemes.
+var DefaultGitSource = new(GitSource)
+
+func init() {
+	RegisterSource("git+https", DefaultGitSource)
+	RegisterSource("git+http", DefaultGitSource)
+	RegisterSource("git+ssh", DefaultGitSource)
+	RegisterSource("git+file", DefaultGitSource)
+}
+
+// isSCPLike reports whether the "repo" is a scp-like git url, e.g. git@github.com:owner/repo.git.
+func isSCPLike(repo string) bool {
+	at, colon := strings.IndexByte(repo, '@'), strings.IndexByte(repo, ':')
+	return at > 0 && colon > at && !strings.Contains(repo, "://")
+}
+
+// gitURL returns the clone url of a git+ prefixed or scp-like "repo".
+func gitURL(repo string) string {
+	return strings.TrimPrefix(repo, "git+")
+}
+
+func (s *GitSource) auth(endpoint string) (transport.AuthMethod, error) {
+	ep, err := transport.NewEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ep.Protocol {
+	case "ssh":
+		user := ep.User
+		if user == "" {
+			user = gitssh.DefaultUsername
+		}
+
+		return gitssh.NewSSHAgentAuth(user)
+	case "http", "https":
+		token := s.Token
+		if token == "" {
+			token = os.Getenv(GitTokenEnv)
+		}
+
+		if token == "" {
+			return nil, nil
+		}
+
+		username := s.Username
+		if username == "" {
+			username = "iris-cli"
+		}
+
+		return &githttp.BasicAuth{Username: username, Password: token}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// isCommitHash reports whether "version" looks like a (short or full) commit hash.
+func isCommitHash(version string) bool {
+	if len(version) < 7 || len(version) > 40 {
+		return false
+	}
+
+	for _, r := range version {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// clone clones the "endpoint" repository into memory and returns the commit of "version".
+func (s *GitSource) clone(endpoint, version string) (*object.Commit, error) {
+	auth, err := s.auth(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := []plumbing.ReferenceName{
+		plumbing.NewBranchReferenceName(version),
+		plumbing.NewTagReferenceName(version),
+	}
+	if version == "main" || version == "master" {
+		// Fallback to the default branch.
+		refs = append(refs, plumbing.HEAD)
+	}
+
+	if len(version) != 40 || !isCommitHash(version) {
+		for _, ref := range refs {
+			r, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
+				URL:           endpoint,
+				Auth:          auth,
+				ReferenceName: ref,
+				SingleBranch:  true,
+				NoCheckout:    true,
+				Depth:         1,
+				Tags:          git.NoTags,
+			})
+			if err != nil {
+				if err == plumbing.ErrReferenceNotFound || strings.Contains(err.Error(), "couldn't find remote ref") {
+					continue
+				}
+
+				return nil, err
+			}
+
+			head, err := r.Head()
+			if err != nil {
+				return nil, err
+			}
+
+			return r.CommitObject(head.Hash())
+		}
+
+		if !isCommitHash(version) {
+			return nil, fmt.Errorf("git: %s: version <%s> not found", endpoint, version)
+		}
+	}
+
+	// Exact (or short) commit, a full clone is required.
+	r, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
+		URL:        endpoint,
+		Auth:       auth,
+		NoCheckout: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(version) == 40 {
+		return r.CommitObject(plumbing.NewHash(version))
+	}
+
+	iter, err := r.CommitObjects()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var found *object.Commit
+	err = iter.ForEach(func(c *object.Commit) error {
+		if strings.HasPrefix(c.Hash.String(), version) {
+			found = c
+			return io.EOF // stop.
+		}
+
+		return nil
+	})
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("git: %s: commit <%s> not found", endpoint, version)
+	}
+
+	return found, nil
+}
+
+// Archive implements the `Source` interface.
+// It clones the repository and compresses the tree of the resolved commit,
+// the `Archive.Commit` field is filled with the full commit hash.
+func (s *GitSource) Archive(repo, version string) (*Archive, error) {
+	endpoint := gitURL(repo)
+
+	commit, err := s.clone(endpoint, version)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := zipCommit(commit, strings.TrimSuffix(path.Base(endpoint), ".git")+"-"+commit.Hash.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Archive{
+		Body:   utils.NoOpReadCloser(bytes.NewReader(b)),
+		Format: ArchiveZip,
+		Commit: commit.Hash.String(),
+	}, nil
+}
+
+// Commit implements the `CommitResolver` interface, it resolves the commit of a branch or a tag.
+// Annotated tags are peeled to their commit, as the `Archive.Commit` is.
+func (s *GitSource) Commit(repo, version string) (string, error) {
+	if len(version) == 40 && isCommitHash(version) {
+		return version, nil
+	}
+
+	refs, peeled, err := s.list(gitURL(repo))
+	if err != nil {
+		return "", err
+	}
+
+	tag := plumbing.NewTagReferenceName(version)
+	if h, ok := peeled[tag.String()]; ok {
+		return h.String(), nil
+	}
+
+	for _, ref := range refs {
+		if name := ref.Name(); name == plumbing.NewBranchReferenceName(version) || name == tag {
+			return ref.Hash().String(), nil
+		}
+	}
+
+	return "", fmt.Errorf("git: %s: version <%s> not found", repo, version)
+}
+
+// list returns the remote references of the "endpoint" and the commits of its annotated tags.
+func (s *GitSource) list(endpoint string) ([]*plumbing.Reference, map[string]plumbing.Hash, error) {
+	auth, err := s.auth(endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ep, err := transport.NewEndpoint(endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c, err := client.NewClient(ep)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sess, err := c.NewUploadPackSession(ep, auth)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer sess.Close()
+
+	ar, err := sess.AdvertisedReferences()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	storage, err := ar.AllReferences()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var refs []*plumbing.Reference
+	for _, ref := range storage {
+		refs = append(refs, ref)
+	}
+
+	return refs, ar.Peeled, nil
+}
+
+// Versions implements the `Source` interface, it lists the remote tags.
+func (s *GitSource) Versions(repo string) []string {
+	refs, _, err := s.list(gitURL(repo))
+	if err != nil {
+		return nil
+	}
+
+	var tags []string
+	for _, ref := range refs {
+		if ref.Name().IsTag() {
+			tags = append(tags, ref.Name().Short())
+		}
+	}
+	sort.Strings(tags)
+
+	return tags
+}
+
+// zipCommit compresses the files of a "commit" under the "root" folder.
+func zipCommit(commit *object.Commit, root string) ([]byte, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+
+	if _, err = w.Create(root + "/"); err != nil {
+		return nil, err
+	}
+
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if f.Mode == filemode.Submodule {
+			return nil
+		}
+
+		mode, err := f.Mode.ToOSFileMode()
+		if err != nil {
+			return err
+		}
+
+		header := &zip.FileHeader{Name: path.Join(root, f.Name), Method: zip.Deflate}
+		header.SetMode(mode)
+
+		fw, err := w.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		r, err := f.Reader()
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(fw, r)
+		r.Close()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,226 @@
+package project
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kataras/iris-cli/utils"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestGitRepoVersion(t *testing.T) {
+	tests := []struct {
+		repo            string
+		expectedName    string
+		expectedVersion string
+		expectedSCPLike bool
+	}{
+		{"basic", "basic", "", false},
+		{"basic@v1.0.0", "basic", "v1.0.0", false},
+		{"basic@feature/x", "basic", "feature/x", false},
+		{"iris-contrib/basic@4f68014", "iris-contrib/basic", "4f68014", false},
+		{"gitlab:owner/repo@v1.0.0", "gitlab:owner/repo", "v1.0.0", false},
+		{"git@github.com:owner/repo.git", "git@github.com:owner/repo.git", "", true},
+		{"git@github.com:owner/repo.git@v1.0.0", "git@github.com:owner/repo.git", "v1.0.0", true},
+		{"git@github.com:owner/repo.git@feature/x", "git@github.com:owner/repo.git", "feature/x", true},
+		{"git+https://github.com/owner/repo.git", "git+https://github.com/owner/repo.git", "", false},
+		{"git+https://user@github.com/owner/repo.git", "git+https://user@github.com/owner/repo.git", "", false},
+		{"git+https://user@github.com/owner/repo.git@feature/x", "git+https://user@github.com/owner/repo.git", "feature/x", false},
+		{"git+ssh://git@github.com/owner/repo.git@v1.0.0", "git+ssh://git@github.com/owner/repo.git", "v1.0.0", false},
+		{"git+file:///tmp/repo@feature/x", "git+file:///tmp/repo", "feature/x", false},
+	}
+
+	for i, tt := range tests {
+		name, version := utils.SplitNameVersion(tt.repo)
+		if name != tt.expectedName || version != tt.expectedVersion {
+			t.Fatalf("[%d] %s: expected name: %q and version: %q but got: %q and %q", i, tt.repo, tt.expectedName, tt.expectedVersion, name, version)
+		}
+
+		if got := isSCPLike(name); got != tt.expectedSCPLike {
+			t.Fatalf("[%d] %s: expected scp-like: %v but got: %v", i, name, tt.expectedSCPLike, got)
+		}
+	}
+
+	for _, s := range []string{"4f68014", "4f68014c4b2bbd4d4e1b2c6e1b0e5d5cba2b5e61"} {
+		if !isCommitHash(s) {
+			t.Fatalf("expected %s to be a commit hash", s)
+		}
+	}
+
+	for _, s := range []string{"v1.0.0", "main", "4f680", "4F68014"} {
+		if isCommitHash(s) {
+			t.Fatalf("expected %s to not be a commit hash", s)
+		}
+	}
+}
+
+// newTestGitRepo creates a bare repository inside the "dir" with a master branch, a feature/x branch,
+// a v1.0.0 lightweight tag and a v1.1.0 annotated tag. It returns the repository and the commits by name.
+func newTestGitRepo(t *testing.T, dir string) (string, map[string]string) {
+	t.Helper()
+
+	// The file transport executes the git-upload-pack and git-receive-pack programs.
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	src := filepath.Join(dir, "src")
+	r, err := git.PlainInit(src, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wt, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig := &object.Signature{Name: "iris-cli", Email: "iris-cli@example.com", When: time.Unix(1600000000, 0)}
+	commit := func(files map[string]string) string {
+		for name, contents := range files {
+			writeTestFile(t, filepath.Join(src, name), contents)
+			if _, err := wt.Add(name); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		h, err := wt.Commit("commit", &git.CommitOptions{Author: sig})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return h.String()
+	}
+
+	commits := map[string]string{
+		"master": commit(map[string]string{"go.mod": "module github.com/owner/repo\n", "main.go": "package main\n"}),
+	}
+
+	if _, err = r.CreateTag("v1.0.0", plumbing.NewHash(commits["master"]), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = r.CreateTag("v1.1.0", plumbing.NewHash(commits["master"]), &git.CreateTagOptions{Tagger: sig, Message: "v1.1.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err = wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature/x"), Create: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits["feature/x"] = commit(map[string]string{"feature.go": "package main\n"})
+
+	bare := filepath.Join(dir, "repo.git")
+	if _, err = git.PlainInit(bare, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = r.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{bare}}); err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.Push(&git.PushOptions{RefSpecs: []config.RefSpec{"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bare, commits
+}
+
+func TestGitSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	bare, commits := newTestGitRepo(t, dir)
+	repo := "git+file://" + filepath.ToSlash(bare)
+
+	src := new(GitSource)
+
+	tests := []struct {
+		version        string
+		expectedCommit string
+		expectedFiles  []string
+	}{
+		{"main", commits["master"], []string{"go.mod", "main.go"}}, // the default branch.
+		{"master", commits["master"], []string{"go.mod", "main.go"}},
+		{"feature/x", commits["feature/x"], []string{"feature.go", "go.mod", "main.go"}},
+		{"v1.0.0", commits["master"], []string{"go.mod", "main.go"}},
+		{"v1.1.0", commits["master"], []string{"go.mod", "main.go"}}, // annotated, peeled to its commit.
+		{commits["feature/x"][:7], commits["feature/x"], []string{"feature.go", "go.mod", "main.go"}},
+		{commits["master"], commits["master"], []string{"go.mod", "main.go"}},
+	}
+
+	for _, tt := range tests {
+		name, version := utils.SplitNameVersion(repo + "@" + tt.version)
+		if name != repo || version != tt.version {
+			t.Fatalf("%s: expected repo: %s and version: %s but got: %s and %s", tt.version, repo, tt.version, name, version)
+		}
+
+		archive, err := src.Archive(name, version)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.version, err)
+		}
+
+		if archive.Commit != tt.expectedCommit {
+			t.Fatalf("%s: expected commit: %s but got: %s", tt.version, tt.expectedCommit, archive.Commit)
+		}
+
+		b, err := ioutil.ReadAll(archive.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var files []string
+		for _, f := range zr.File {
+			if name := f.Name[strings.IndexByte(f.Name, '/')+1:]; name != "" {
+				files = append(files, name)
+			}
+		}
+		sort.Strings(files)
+
+		if strings.Join(files, ",") != strings.Join(tt.expectedFiles, ",") {
+			t.Fatalf("%s: expected files: %v but got: %v", tt.version, tt.expectedFiles, files)
+		}
+
+		if tt.version == "main" || len(tt.version) < 40 && isCommitHash(tt.version) {
+			continue
+		}
+
+		commit, err := src.Commit(repo, tt.version)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.version, err)
+		}
+
+		if commit != tt.expectedCommit {
+			t.Fatalf("%s: expected resolved commit: %s but got: %s", tt.version, tt.expectedCommit, commit)
+		}
+	}
+
+	if _, err = src.Archive(repo, "v9.9.9"); err == nil {
+		t.Fatalf("expected an error on unknown version")
+	}
+
+	if expected, got := []string{"v1.0.0", "v1.1.0"}, src.Versions(repo); strings.Join(expected, ",") != strings.Join(got, ",") {
+		t.Fatalf("expected versions: %v but got: %v", expected, got)
+	}
+}
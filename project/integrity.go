@@ -0,0 +1,119 @@
+package project
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SignatureExt is the extension of a registry's detached signature file,
+// e.g. "registry.yml.sig" for the "registry.yml" registry.
+const SignatureExt = ".sig"
+
+// signatureEndpoint returns the endpoint of the detached signature of a registry's "endpoint".
+// The `SignatureExt` is appended to the path of a URL, so its query is kept, e.g. a "?token=" of a private registry.
+func signatureEndpoint(endpoint string) string {
+	if strings.HasPrefix(endpoint, "http") {
+		if u, err := url.Parse(endpoint); err == nil {
+			u.Path += SignatureExt
+			if u.RawPath != "" {
+				u.RawPath += SignatureExt
+			}
+			return u.String()
+		}
+	}
+
+	return endpoint + SignatureExt
+}
+
+// Integrity errors.
+var (
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	ErrChecksumMissing  = errors.New("no pinned checksum")
+	ErrSignatureInvalid = errors.New("signature does not match any trusted key")
+	ErrSignatureMissing = errors.New("signature is missing")
+	ErrNoTrustedKeys    = errors.New("no trusted keys")
+)
+
+// Checksum returns the hex-encoded SHA-256 of "b".
+func Checksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyChecksum reports whether the SHA-256 of "b" matches the "expected" one,
+// which may be prefixed with "sha256:".
+func verifyChecksum(b []byte, expected string) error {
+	expected = strings.TrimPrefix(strings.TrimSpace(expected), "sha256:")
+	if got := Checksum(b); !strings.EqualFold(got, expected) {
+		return fmt.Errorf("%w: expected sha256:%s but got sha256:%s", ErrChecksumMismatch, expected, got)
+	}
+
+	return nil
+}
+
+// GenerateKey generates a new ed25519 key pair for signing registries,
+// the keys are base64 encoded.
+func GenerateKey() (publicKey string, privateKey string, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(pub), base64.StdEncoding.EncodeToString(priv), nil
+}
+
+// ParsePublicKey decodes a base64 encoded ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid ed25519 public key: %q", s)
+	}
+
+	return ed25519.PublicKey(b), nil
+}
+
+// ParsePrivateKey decodes a base64 encoded ed25519 private key.
+func ParsePrivateKey(s string) (ed25519.PrivateKey, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(b) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid ed25519 private key")
+	}
+
+	return ed25519.PrivateKey(b), nil
+}
+
+// Sign returns the base64 encoded signature of a registry file's "body",
+// to be stored next to the registry file with the `SignatureExt` extension.
+func Sign(body []byte, privateKey ed25519.PrivateKey) []byte {
+	sig := ed25519.Sign(privateKey, body)
+	return []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+}
+
+// VerifySignature reports whether the base64 encoded "sig" is a signature of "body"
+// by any of the "trustedKeys".
+func VerifySignature(body, sig []byte, trustedKeys []ed25519.PublicKey) error {
+	if len(trustedKeys) == 0 {
+		return ErrNoTrustedKeys
+	}
+
+	b, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(sig)))
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+
+	for _, key := range trustedKeys {
+		if ed25519.Verify(key, body, b) {
+			return nil
+		}
+	}
+
+	return ErrSignatureInvalid
+}
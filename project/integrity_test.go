@@ -0,0 +1,98 @@
+package project
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRegistrySignature(t *testing.T) {
+	publicKey, privateKey, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub, err := ParsePublicKey(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	priv, err := ParsePrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := []byte("Projects:\n  basic: iris-contrib/basic-template\n")
+	files := map[string][]byte{
+		"./registry.yml":                body,
+		"./registry.yml" + SignatureExt: Sign(body, priv),
+		"./tampered.yml":                append(body, "  evil: evil/template\n"...),
+		"./tampered.yml" + SignatureExt: Sign(body, priv),
+		"./unsigned.yml":                body,
+		"https://raw.example.com/owner/registry/main/registry.yml?token=abc":     body,
+		"https://raw.example.com/owner/registry/main/registry.yml.sig?token=abc": Sign(body, priv),
+	}
+
+	newRegistry := func(endpoint string) *Registry {
+		reg := NewRegistry()
+		reg.Endpoint = endpoint
+		reg.TrustedKeys = []ed25519.PublicKey{pub}
+		reg.Verify = true
+		reg.EndpointAsset = func(name string) ([]byte, error) {
+			b, ok := files[name]
+			if !ok {
+				return nil, fmt.Errorf("%s: not found", name)
+			}
+			return b, nil
+		}
+		return reg
+	}
+
+	if err = newRegistry("./registry.yml").Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The signature of a URL with a query, e.g. a private registry's token.
+	if err = newRegistry("https://raw.example.com/owner/registry/main/registry.yml?token=abc").Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = newRegistry("./tampered.yml").Load(); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("expected error: %v but got: %v", ErrSignatureInvalid, err)
+	}
+
+	if err = newRegistry("./unsigned.yml").Load(); !errors.Is(err, ErrSignatureMissing) {
+		t.Fatalf("expected error: %v but got: %v", ErrSignatureMissing, err)
+	}
+
+	// Not on verify mode.
+	reg := newRegistry("./unsigned.yml")
+	reg.Verify = false
+	if err = reg.Load(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProjectVerifyArchive(t *testing.T) {
+	b := []byte("archive")
+
+	p := &Project{Name: "basic", Version: "main", ExpectedSHA256: "sha256:" + Checksum(b), Verify: true}
+	if err := p.verifyArchive(b); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := Checksum(b), p.SHA256; expected != got {
+		t.Fatalf("expected recorded checksum: %s but got: %s", expected, got)
+	}
+
+	p.ExpectedSHA256 = Checksum([]byte("other"))
+	if err := p.verifyArchive(b); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected error: %v but got: %v", ErrChecksumMismatch, err)
+	}
+
+	p.ExpectedSHA256 = ""
+	if err := p.verifyArchive(b); !errors.Is(err, ErrChecksumMissing) {
+		t.Fatalf("expected error: %v but got: %v", ErrChecksumMissing, err)
+	}
+}
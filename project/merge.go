@@ -0,0 +1,171 @@
+package project
+
+import (
+	"bytes"
+	"strings"
+)
+
+// splitLines splits "text" after each new line.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// matchLines returns the index of the matching "other" line for each "base" line, or -1,
+// based on the shortest edit script of the Myers' diff algorithm.
+func matchLines(base, other []string) []int {
+	matches := make([]int, len(base))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	var (
+		n, m   = len(base), len(other)
+		max    = n + m
+		offset = max + 1
+		v      = make([]int, 2*max+3)
+		trace  [][]int
+	)
+
+search:
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // insertion.
+			} else {
+				x = v[offset+k-1] + 1 // deletion.
+			}
+
+			y := x - k
+			for x < n && y < m && base[x] == other[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk back the edit path and record the diagonal moves (the equal lines).
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			matches[x] = y
+		}
+
+		x, y = prevX, prevY
+	}
+
+	return matches
+}
+
+// merge3 performs a line based three-way merge of the "local" and "other" changes
+// made to the "base" text. Conflicting changes are written between standard conflict markers,
+// labeled with "localLabel" and "otherLabel". It returns the merged text and the number of conflicts.
+func merge3(base, local, other []byte, localLabel, otherLabel string) ([]byte, int) {
+	var (
+		baseLines  = splitLines(string(base))
+		localLines = splitLines(string(local))
+		otherLines = splitLines(string(other))
+
+		localMatches = matchLines(baseLines, localLines)
+		otherMatches = matchLines(baseLines, otherLines)
+
+		out       = new(bytes.Buffer)
+		conflicts int
+	)
+
+	writeLines := func(lines []string) {
+		for _, line := range lines {
+			out.WriteString(line)
+		}
+	}
+
+	equal := func(a, b []string) bool {
+		return strings.Join(a, "") == strings.Join(b, "")
+	}
+
+	ib, il, ir := 0, 0, 0
+	for {
+		// Stable lines, unchanged on both sides.
+		for ib < len(baseLines) && localMatches[ib] == il && otherMatches[ib] == ir {
+			out.WriteString(baseLines[ib])
+			ib++
+			il++
+			ir++
+		}
+
+		// Find the next stable base line.
+		o := ib
+		for o < len(baseLines) && (localMatches[o] == -1 || otherMatches[o] == -1) {
+			o++
+		}
+
+		l, r := len(localLines), len(otherLines)
+		if o < len(baseLines) {
+			l, r = localMatches[o], otherMatches[o]
+		}
+
+		var (
+			baseChunk  = baseLines[ib:o]
+			localChunk = localLines[il:l]
+			otherChunk = otherLines[ir:r]
+		)
+
+		switch {
+		case equal(localChunk, baseChunk):
+			writeLines(otherChunk)
+		case equal(otherChunk, baseChunk), equal(localChunk, otherChunk):
+			writeLines(localChunk)
+		default:
+			conflicts++
+			out.WriteString("<<<<<<< " + localLabel + "\n")
+			writeLines(withNewLine(localChunk))
+			out.WriteString("=======\n")
+			writeLines(withNewLine(otherChunk))
+			out.WriteString(">>>>>>> " + otherLabel + "\n")
+		}
+
+		if o >= len(baseLines) {
+			break
+		}
+
+		ib, il, ir = o, l, r
+	}
+
+	return out.Bytes(), conflicts
+}
+
+// withNewLine makes sure that the last line ends with a new line.
+func withNewLine(lines []string) []string {
+	if n := len(lines); n > 0 && !strings.HasSuffix(lines[n-1], "\n") {
+		lines = append(lines[:n-1:n-1], lines[n-1]+"\n")
+	}
+
+	return lines
+}
@@ -0,0 +1,90 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// MirrorRegistryFilename is the registry file of a mirror's directory, see `Registry.Mirror`.
+const MirrorRegistryFilename = "registry.yml"
+
+// Mirror downloads the archives of the registry's projects of "names" (all when empty)
+// under the "dir"/archives directory, so they can be installed without a network access.
+// The "versions" maps project names to the version to download,
+// it defaults to the project's default branch or "main".
+//
+// A non-empty "dir" is accepted only if it is a previous mirror, it contains a `MirrorRegistryFilename`.
+//
+// It returns a registry of the downloaded projects to be saved as "dir"/registry.yml,
+// their repositories point at the local archives (file://, relative to the "dir"), their default branch is the downloaded version and their checksums are pinned.
+// The projects which could not be downloaded are reported as issues.
+func (r *Registry) Mirror(dir string, names []string, versions map[string]string) (*Registry, []*RegistryIssue, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 && !utils.Exists(filepath.Join(dir, MirrorRegistryFilename)) {
+		return nil, nil, fmt.Errorf("mirror <%s>: directory is not empty and it is not a mirror", dir)
+	}
+
+	if len(names) == 0 {
+		names = r.Names
+	}
+
+	mirror := NewRegistry()
+	mirror.Checksums = make(map[string]string)
+	var issues []*RegistryIssue
+
+	for _, name := range names {
+		project, ok := r.Projects[name]
+		if !ok {
+			issues = append(issues, &RegistryIssue{Name: name, Err: ErrProjectNotExists})
+			continue
+		}
+
+		// An empty version is resolved to the project's default branch or "main".
+		p := &Project{Name: name, Version: versions[name], Cache: r.Cache, Offline: r.Offline}
+		if err = r.Resolve(p); err != nil {
+			issues = append(issues, &RegistryIssue{Name: name, Err: err})
+			continue
+		}
+
+		b, format, err := p.download()
+		if err != nil {
+			issues = append(issues, &RegistryIssue{Name: name, Err: fmt.Errorf("version <%s>: %v", p.Version, err)})
+			continue
+		}
+
+		archiveFile := filepath.Join(dir, "archives", filepath.FromSlash(name), strings.ReplaceAll(p.Version, "/", "_")+format)
+		if err = os.MkdirAll(filepath.Dir(archiveFile), os.ModePerm); err != nil {
+			return nil, nil, err
+		}
+
+		if err = os.WriteFile(archiveFile, b, 0644); err != nil {
+			return nil, nil, err
+		}
+
+		_, subdir := SplitSubdir(p.Repo)
+
+		rel, err := filepath.Rel(dir, archiveFile)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mirrored := *project
+		// Relative to the registry file, see `Registry.Resolve`.
+		mirrored.Repo = JoinSubdir("file://./"+filepath.ToSlash(rel), subdir)
+		mirrored.DefaultBranch = p.Version
+		mirrored.Source = ""
+		mirror.Projects[name] = &mirrored
+		mirror.Checksums[name+"@"+p.Version] = "sha256:" + Checksum(b)
+	}
+
+	mirror.sortNames()
+	return mirror, issues, nil
+}
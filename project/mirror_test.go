@@ -0,0 +1,118 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+func TestRegistryMirror(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	archiveFile := filepath.Join(dir, "app.zip")
+	err = ioutil.WriteFile(archiveFile, newTestZip(t,
+		testZipEntry{Name: "app-main/main.go", Contents: "package main\n", Mode: 0644},
+	), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg := NewRegistry()
+	reg.Projects = map[string]*RegistryProject{
+		"app":     {Repo: "file://" + filepath.ToSlash(archiveFile), Description: "App", DefaultBranch: "master"},
+		"missing": {Repo: "file://" + filepath.ToSlash(filepath.Join(dir, "missing.zip"))},
+	}
+	reg.sortNames()
+
+	out := filepath.Join(dir, "mirror")
+	mirror, issues, err := reg.Mirror(out, nil, map[string]string{"app": "v1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := 1, len(issues); expected != got {
+		t.Fatalf("expected %d issues but got %d: %v", expected, got, issues)
+	}
+
+	if expected, got := "missing", issues[0].Name; expected != got {
+		t.Fatalf("expected an issue of project <%s> but got <%s>", expected, got)
+	}
+
+	if err = mirror.SaveFile(filepath.Join(out, "registry.yml")); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewRegistry()
+	loaded.Endpoint = filepath.Join(out, "registry.yml")
+	if err = loaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	project := loaded.Projects["app"]
+	if project == nil {
+		t.Fatalf("expected project <app> to be mirrored")
+	}
+
+	if expected, got := "file://./archives/app/v1.0.0.zip", project.Repo; expected != got {
+		t.Fatalf("expected repo: %s but got: %s", expected, got)
+	}
+
+	if expected, got := "v1.0.0", project.DefaultBranch; expected != got {
+		t.Fatalf("expected default branch: %s but got: %s", expected, got)
+	}
+
+	if expected, got := "App", project.Description; expected != got {
+		t.Fatalf("expected description: %s but got: %s", expected, got)
+	}
+
+	if sum := loaded.Checksums["app@v1.0.0"]; !strings.HasPrefix(sum, "sha256:") {
+		t.Fatalf("expected a pinned checksum but got: %q", sum)
+	}
+
+	p := &Project{Name: "app", Dest: filepath.Join(dir, "app"), Module: "github.com/author/app"}
+	if err = loaded.Resolve(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "file://"+filepath.ToSlash(filepath.Join(out, "archives", "app", "v1.0.0.zip")), p.Repo; expected != got {
+		t.Fatalf("expected the repo to be resolved relative to the registry file: %s but got: %s", expected, got)
+	}
+
+	if p.ExpectedSHA256 == "" {
+		t.Fatalf("expected the pinned checksum to be resolved")
+	}
+
+	if err = p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "package main\n", readTestFile(t, filepath.Join(p.Dest, "main.go")); expected != got {
+		t.Fatalf("expected main.go contents: %q but got: %q", expected, got)
+	}
+
+	// A previous mirror is updated.
+	if _, _, err = reg.Mirror(out, []string{"app"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if !utils.Exists(filepath.Join(out, "archives", "app", "master.zip")) {
+		t.Fatalf("expected the default branch to be mirrored")
+	}
+
+	// Any other non-empty directory is kept.
+	if _, _, err = reg.Mirror(p.Dest, []string{"app"}, nil); err == nil {
+		t.Fatalf("expected an error on a non-empty directory which is not a mirror")
+	}
+
+	if utils.Exists(filepath.Join(p.Dest, "archives")) {
+		t.Fatalf("expected the non-empty directory to be untouched")
+	}
+}
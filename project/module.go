@@ -0,0 +1,224 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// DefaultRewriteFiles is the default list of glob patterns of the non-Go files
+// which are textually rewritten when the module path changes.
+var DefaultRewriteFiles = []string{
+	"*.md",
+	"*.yml",
+	"*.yaml",
+	"*.json",
+	"*.toml",
+	"*.proto",
+	"Dockerfile",
+	"Makefile",
+}
+
+// moduleRewriter replaces an old module path with a new one,
+// e.g. "github.com/author/app/v2" with "github.com/me/app".
+type moduleRewriter struct {
+	oldPath string
+	newPath string
+}
+
+// replacePath returns the rewritten import or module "p" path
+// if it is the old module path or one of its packages.
+// Packages of a different major version of the old module,
+// e.g. "github.com/author/app/v3/x", are left as they are.
+func (m *moduleRewriter) replacePath(p string) (string, bool) {
+	if p == m.oldPath {
+		return m.newPath, true
+	}
+
+	if !strings.HasPrefix(p, m.oldPath+"/") {
+		return p, false
+	}
+
+	rest := strings.TrimPrefix(p, m.oldPath+"/")
+	if _, major, ok := module.SplitPathVersion(m.oldPath + "/" + strings.SplitN(rest, "/", 2)[0]); ok && major != "" {
+		return p, false
+	}
+
+	return m.newPath + "/" + rest, true
+}
+
+// rewriteGoFile rewrites the import paths of a Go source file.
+// It reports false when nothing changed or the file cannot be parsed.
+func (m *moduleRewriter) rewriteGoFile(filename string, src []byte) ([]byte, bool) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, false
+	}
+
+	changed := false
+	for _, spec := range f.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		if newPath, ok := m.replacePath(importPath); ok {
+			spec.Path.Value = strconv.Quote(newPath)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil, false
+	}
+
+	buf := new(bytes.Buffer)
+	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if err = cfg.Fprint(buf, fset, f); err != nil {
+		return nil, false
+	}
+
+	return buf.Bytes(), true
+}
+
+// rewriteGoMod rewrites the module directive and the required and replaced module paths of a go.mod file.
+func (m *moduleRewriter) rewriteGoMod(filename string, data []byte) ([]byte, bool, error) {
+	f, err := modfile.Parse(filename, data, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	changed := false
+	if f.Module != nil {
+		if newPath, ok := m.replacePath(f.Module.Mod.Path); ok {
+			if err = f.AddModuleStmt(newPath); err != nil {
+				return nil, false, err
+			}
+			changed = true
+		}
+	}
+
+	for _, r := range f.Require {
+		if newPath, ok := m.replacePath(r.Mod.Path); ok {
+			if err = f.AddRequire(newPath, r.Mod.Version); err != nil {
+				return nil, false, err
+			}
+			if err = f.DropRequire(r.Mod.Path); err != nil {
+				return nil, false, err
+			}
+			changed = true
+		}
+	}
+
+	for _, r := range f.Replace {
+		oldPath, oldOK := m.replacePath(r.Old.Path)
+		newPath, newOK := r.New.Path, false
+		if r.New.Version != "" { // not a local directory.
+			newPath, newOK = m.replacePath(r.New.Path)
+		}
+
+		if !oldOK && !newOK {
+			continue
+		}
+
+		if err = f.DropReplace(r.Old.Path, r.Old.Version); err != nil {
+			return nil, false, err
+		}
+		if err = f.AddReplace(oldPath, r.Old.Version, newPath, r.New.Version); err != nil {
+			return nil, false, err
+		}
+		changed = true
+	}
+
+	if !changed {
+		return nil, false, nil
+	}
+
+	f.Cleanup()
+	b, err := f.Format()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return b, true, nil
+}
+
+// shouldRewriteText reports whether the slash-separated "name" file
+// matches one of the "patterns", by its base name or its full name.
+func shouldRewriteText(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, path.Base(name)); matched {
+			return true
+		}
+
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// VerifyError is returned when the installed project
+// cannot be loaded by the go tool after its module path was rewritten.
+type VerifyError struct {
+	Output string
+	Err    error
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("go list: %v: %s", e.Err, strings.TrimSpace(e.Output))
+}
+
+func (e *VerifyError) Unwrap() error {
+	return e.Err
+}
+
+// verifyModule runs "go list -deps ./..." inside the "dir" directory.
+// If "resolve" is true then the missing requirements are added to the go.mod file.
+// It does nothing if the go tool is not installed or the directory is not a go module.
+func verifyModule(dir string, resolve bool) error {
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+		return nil
+	}
+
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	args := []string{"list", "-deps"}
+	if resolve {
+		args = append(args, "-mod=mod")
+	}
+
+	cmd := exec.CommandContext(ctx, goBin, append(args, "./...")...)
+	cmd.Dir = dir
+	cmd.Stdout = ioutil.Discard
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+
+	if err = cmd.Run(); err != nil {
+		return &VerifyError{Output: stderr.String(), Err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package project
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestModuleRewriterReplacePath(t *testing.T) {
+	m := &moduleRewriter{oldPath: "github.com/author/app", newPath: "github.com/me/myapp/v2"}
+
+	tests := []struct {
+		path     string
+		expected string
+		ok       bool
+	}{
+		{"github.com/author/app", "github.com/me/myapp/v2", true},
+		{"github.com/author/app/routes", "github.com/me/myapp/v2/routes", true},
+		{"github.com/author/application", "github.com/author/application", false},
+		{"github.com/author/app/v3/routes", "github.com/author/app/v3/routes", false},
+		{"github.com/kataras/iris/v12", "github.com/kataras/iris/v12", false},
+	}
+
+	for i, tt := range tests {
+		got, ok := m.replacePath(tt.path)
+		if got != tt.expected || ok != tt.ok {
+			t.Fatalf("[%d] expected: %q (%v) but got: %q (%v)", i, tt.expected, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestModuleRewriterGoFile(t *testing.T) {
+	m := &moduleRewriter{oldPath: "github.com/author/app", newPath: "github.com/me/myapp"}
+
+	src := `package main
+
+import (
+	"fmt"
+
+	"github.com/author/app/routes"
+)
+
+// Docs at https://github.com/author/app.
+const url = "https://github.com/author/app"
+
+func main() {
+	fmt.Println(routes.Name, url)
+}
+`
+
+	b, ok := m.rewriteGoFile("main.go", []byte(src))
+	if !ok {
+		t.Fatalf("expected the file to be rewritten")
+	}
+
+	expected := strings.Replace(src, `"github.com/author/app/routes"`, `"github.com/me/myapp/routes"`, 1)
+	if got := string(b); got != expected {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+}
+
+func TestModuleRewriterGoMod(t *testing.T) {
+	m := &moduleRewriter{oldPath: "github.com/author/app/v2", newPath: "github.com/me/myapp"}
+
+	data := `module github.com/author/app/v2
+
+go 1.23
+
+require (
+	github.com/author/app/v2/tools v0.0.0
+	github.com/kataras/iris/v12 v12.2.11
+)
+
+replace github.com/author/app/v2/tools => ./tools
+`
+
+	b, ok, err := m.rewriteGoMod("go.mod", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("expected go.mod to be rewritten")
+	}
+
+	got := string(b)
+	for _, s := range []string{
+		"module github.com/me/myapp\n",
+		"github.com/me/myapp/tools v0.0.0",
+		"github.com/kataras/iris/v12 v12.2.11",
+		"replace github.com/me/myapp/tools => ./tools",
+	} {
+		if !strings.Contains(got, s) {
+			t.Fatalf("expected go.mod to contain: %q but got:\n%s", s, got)
+		}
+	}
+
+	if strings.Contains(got, "github.com/author/app") {
+		t.Fatalf("expected old module path to be removed but got:\n%s", got)
+	}
+}
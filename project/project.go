@@ -1,7 +1,6 @@
 package project
 
 import (
-	"archive/zip"
 	"bytes"
 	"context"
 	"crypto/md5"
@@ -22,20 +21,58 @@ import (
 
 	"github.com/kataras/golog"
 	"golang.org/x/sync/errgroup"
-	"gopkg.in/yaml.v3"
 )
 
 type Project struct {
 	Name string `json:"name,omitempty" yaml:"Name" toml:"Name"` // e.g. starter-kit
 	// Remote.
-	Repo    string `json:"repo" yaml:"Repo" toml:"Repo"`                    // e.g. "iris-contrib/starter-kit"
-	Version string `json:"version,omitempty" yaml:"Version" toml:"Version"` // if empty then set to "main"
+	Repo    string `json:"repo" yaml:"Repo" toml:"Repo"`                           // e.g. "iris-contrib/starter-kit"
+	Version string `json:"version,omitempty" yaml:"Version" toml:"Version"`        // if empty then set to "main"
+	Commit  string `json:"commit,omitempty" yaml:"Commit,omitempty" toml:"Commit"` // the resolved commit hash of the installed version, if known.
+	SHA256  string `json:"sha256,omitempty" yaml:"SHA256,omitempty" toml:"SHA256"` // the checksum of the downloaded archive.
+	// ExpectedSHA256, if not empty, is the pinned checksum of the archive, e.g. by a registry.
+	ExpectedSHA256 string `json:"-" yaml:"-" toml:"-"`
+	// Verify set to true to refuse to install an archive without a matching pinned checksum.
+	Verify bool `json:"-" yaml:"-" toml:"-"`
+	// Git set to true to clone the repository instead of downloading its archive.
+	Git bool `json:"-" yaml:"-" toml:"-"`
+	// Cache, if not nil, stores the downloaded archives and
+	// serves them when the resolved commit of the version is already cached.
+	Cache *Cache `json:"-" yaml:"-" toml:"-"`
+	// Offline set to true to install the archive from the Cache only.
+	Offline bool `json:"-" yaml:"-" toml:"-"`
 	// Local.
-	Dest         string            `json:"dest,omitempty" yaml:"Dest" toml:"Dest"`       // if empty then $GOPATH+Module or ./+Module, absolute path of project destination.
-	Module       string            `json:"module,omitempty" yaml:"Module" toml:"Module"` // if empty then set to the remote module name fetched from go.mod
-	Replacements map[string]string `json:"-" yaml:"-" toml:"-"`                          // any raw text replacements.
+	Dest   string `json:"-" yaml:"-" toml:"-"`                          // if empty then $GOPATH+Module or ./+Module, absolute path of project destination. Local state.
+	Module string `json:"module,omitempty" yaml:"Module" toml:"Module"` // if empty then set to the remote module name fetched from go.mod
+	// Answers are the values of the template variables, see `Template`.
+	Answers      map[string]interface{} `json:"answers,omitempty" yaml:"Answers,omitempty" toml:"Answers"`
+	Replacements map[string]string      `json:"-" yaml:"-" toml:"-"` // any raw text replacements.
+	// MaxSize is the maximum total uncompressed size of the project's archive.
+	// Defaults to `DefaultMaxSize`.
+	MaxSize int64 `json:"-" yaml:"-" toml:"-"`
+	// MaxFiles is the maximum number of files and directories of the project's archive.
+	// Defaults to `DefaultMaxFiles`.
+	MaxFiles int `json:"-" yaml:"-" toml:"-"`
+	// RewriteFiles is a list of glob patterns of the non-Go files
+	// which the old module path is textually replaced with the new one.
+	// Defaults to `DefaultRewriteFiles`.
+	RewriteFiles []string `json:"-" yaml:"-" toml:"-"`
+	// NoVerify set to true to skip the "go list" check of a project which its module path was rewritten.
+	NoVerify bool `json:"-" yaml:"-" toml:"-"`
+	// OnConflict is the strategy of the project's files which already exist in the destination directory,
+	// one of `ConflictSkip`, `ConflictBackup` and `ConflictNew`. Defaults to `ConflictBackup`.
+	OnConflict string `json:"-" yaml:"-" toml:"-"`
+	// Conflicts maps glob patterns of files to their conflict strategy, it takes precedence over the `OnConflict`.
+	Conflicts map[string]string `json:"-" yaml:"-" toml:"-"`
+	// ResolveConflict, if not nil, is called for each existing file which does not match any of the `Conflicts`
+	// to ask for its conflict strategy. An empty strategy falls back to the `OnConflict`.
+	ResolveConflict func(name string) (string, error) `json:"-" yaml:"-" toml:"-"`
 	// Pre Installation.
 	Reader func(io.Reader) ([]byte, error) `json:"-" yaml:"-" toml:"-"`
+	// Prompt, if not nil, is called when the project contains a template manifest
+	// to ask for the variables which are not already answered.
+	// It should return the validated answers, see `Template.Answers`.
+	Prompt func(t *Template, answers map[string]interface{}) (map[string]interface{}, error) `json:"-" yaml:"-" toml:"-"`
 	// Post installation.
 	// DisableInlineCommands disables source code comments stats with // $ _command_ to execute on "run" command.
 	DisableInlineCommands bool `json:"disable_inline_commands" yaml:"DisableInlineCommands" toml:"DisableInlineCommands"`
@@ -52,22 +89,38 @@ type Project struct {
 	Watcher    Watcher     `json:"watcher" yaml:"Watcher" toml:"Watcher"`
 	LiveReload *LiveReload `json:"livereload" yaml:"LiveReload" toml:"LiveReload"`
 
+	// Profiles are the named environments of the project, e.g. "dev" and "staging", see `Profile`.
+	Profiles map[string]*Profile `json:"profiles,omitempty" yaml:"Profiles,omitempty" toml:"Profiles"`
+	// Profile is the name of the profile which is applied on `Run`, if not empty.
+	Profile string `json:"-" yaml:"-" toml:"-"`
+	// env is the resolved environment of the executed commands, see `loadEnv`.
+	env []string
+
 	// Relative path of the files and directories installed, because the folder may be not empty
 	// and when installation fails we don't want to delete any user-defined files,
 	// just the project's ones before build.
-	Files          []string `json:"files,omitempty" yaml:"Files" toml:"Files"`
-	BuildFiles     []string `json:"build_files" yaml:"BuildFiles" toml:"BuildFiles"` // New directories and files, relatively to p.Dest, that are created by build (makefile, build script, npm install & npm run build).
-	MD5PackageJSON []byte   `json:"md5_package_json" yaml:"MD5PackageJSON" toml:"MD5PackageJSON"`
+	Files []string `json:"files,omitempty" yaml:"Files" toml:"Files"`
+
+	// Local state, stored in the `StateFilename` instead of the `ProjectFilename`.
+	BuildFiles     []string `json:"-" yaml:"-" toml:"-"` // New directories and files, relatively to p.Dest, that are created by build (makefile, build script, npm install & npm run build).
+	MD5PackageJSON []byte   `json:"-" yaml:"-" toml:"-"`
 
 	runner *exec.Cmd
 
 	// Running is set automatically to true on `Run` and false on interrupt,
 	// it is used for third-parties software to check if a specific project is running under iris-cli.
-	Running        bool `json:"running" yaml:"Running,omitempty" toml:"Running"`
+	Running        bool `json:"-" yaml:"-" toml:"-"`
 	stdout, stderr io.Writer
 
 	// runningCommands chan context.CancelFunc
 	frontEndRunningCommands map[*exec.Cmd]context.CancelFunc
+
+	// configFile is the project's configuration file, see `ConfigFile`.
+	configFile string
+	// goModSynthesized is true when the installed subdirectory had no go.mod file.
+	goModSynthesized bool
+	// staged keeps the extracted files in memory on `DryRun`.
+	staged map[string]*stagedFile
 }
 
 type Watcher struct {
@@ -81,8 +134,15 @@ type Watcher struct {
 	IgnoreDirs []string `json:"ignore_dirs" yaml:"IgnoreDirs" toml:"IgnoreDirs"`
 }
 
-// ProjectFilename is the current project's filename that is created on project creation (and automatically added to a .gitignore file if a git repository).
-const ProjectFilename = ".iris.yml"
+// ProjectFilename is the project's configuration file which is created on project creation,
+// it contains the settings which are shared by the project's contributors and it should be committed.
+// The machine-local state of the project is stored in the `StateFilename`.
+const ProjectFilename = "iris.yml"
+
+// LegacyProjectFilename is the project file of the previous versions,
+// it contained both the configuration and the local state.
+// It is migrated to the `ProjectFilename` and the `StateFilename` on `LoadFromDisk`.
+const LegacyProjectFilename = ".iris.yml"
 
 func (p *Project) setDefaults() {
 	if p.LiveReload == nil {
@@ -130,25 +190,23 @@ func (p *Project) setDefaults() {
 	}
 }
 
+// SaveToDisk writes the project's configuration to its `ConfigFile`
+// and its local state to the `StateFilename`.
 func (p *Project) SaveToDisk() error {
 	p.setDefaults()
 
-	projectFile := filepath.Join(p.Dest, ProjectFilename)
-
-	outFile, err := os.OpenFile(projectFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
-	if err != nil {
+	if err := utils.Export(filepath.Join(p.Dest, p.ConfigFile()), p); err != nil {
 		return err
 	}
-	defer outFile.Close()
 
-	enc := yaml.NewEncoder(outFile)
-	return enc.Encode(p)
-	// enc := gob.NewEncoder(outFile)
-	// return enc.Encode(p)
+	return p.SaveState()
 }
 
 var ErrProjectFileNotExist = errors.New("project file does not exist")
 
+// LoadFromDisk reads the project of the "path" directory, its configuration from whichever
+// of the `ProjectFilenames` exists and its local state, if exists, from the `StateFilename`.
+// A `LegacyProjectFilename` is migrated to them.
 func LoadFromDisk(path string) (*Project, error) {
 	projectPath, err := filepath.Abs(path)
 	if err != nil {
@@ -163,216 +221,558 @@ func LoadFromDisk(path string) (*Project, error) {
 		projectPath = filepath.Dir(projectPath)
 	}
 
-	projectFile := filepath.Join(projectPath, ProjectFilename)
-	if !utils.Exists(projectFile) {
+	projectFile, err := findProjectFile(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if projectFile == "" {
+		if utils.Exists(filepath.Join(projectPath, LegacyProjectFilename)) {
+			return migrateLegacyProject(projectPath)
+		}
+
 		return nil, ErrProjectFileNotExist
 	}
 
-	inFile, err := os.OpenFile(projectFile, os.O_RDONLY, os.ModePerm)
+	p := &Project{configFile: projectFile}
+	body, err := readProjectFile(filepath.Join(projectPath, projectFile), p)
 	if err != nil {
 		return nil, err
 	}
-	defer inFile.Close()
 
-	p := new(Project)
-	// dec := gob.NewDecoder(inFile)
-	// err = dec.Decode(p)
-	dec := yaml.NewDecoder(inFile)
-	err = dec.Decode(p)
-	if err != nil {
-		return nil, err
+	state := new(projectState)
+	if stateFile := filepath.Join(projectPath, filepath.FromSlash(StateFilename)); utils.Exists(stateFile) {
+		if err = utils.Import(stateFile, state); err != nil {
+			return nil, err
+		}
 	}
+	p.setState(state, projectPath)
 
 	p.setDefaults()
+	if err = p.validate(body); err != nil {
+		return nil, err
+	}
+
 	return p, nil
 }
 
-func (p *Project) Install() error {
-	b, err := p.download()
+// Install downloads and installs the project to its destination directory.
+// The archive is extracted and rewritten to a staging directory first
+// and its files are moved to the destination only on success.
+// Existing destination files are handled based on their conflict strategy, see `OnConflict`,
+// and any of them which are overwritten or renamed are restored on failure.
+func (p *Project) Install() (err error) {
+	b, format, err := p.download()
+	if err != nil {
+		return err
+	}
+
+	p.Dest = utils.Dest(p.Dest)
+
+	// The destination is created first, the staging directory is inside it.
+	tx, err := newTransaction(p.Dest)
 	if err != nil {
 		return err
 	}
 
 	defer func() {
 		if err != nil {
-			// Remove any installed files on errors.
-			err = p.Unistall()
+			// Remove any installed files and restore the overwritten ones on errors.
+			tx.rollback()
+			return
 		}
+
+		tx.close()
 	}()
 
-	err = p.unzip(b)
+	stagingDir, err := tempDir(p.Dest, ".iris-staging-")
+	if err != nil {
+		return err
+	}
+	defer removeTempDir(p.Dest, stagingDir)
+
+	rewritten, err := p.stage(b, format, stagingDir)
+	if err != nil {
+		return err
+	}
+
+	files, err := p.planInstall(func(staged string) (bool, error) {
+		info, err := os.Lstat(filepath.Join(stagingDir, filepath.FromSlash(staged)))
+		if err != nil {
+			return false, err
+		}
+
+		return info.IsDir(), nil
+	})
 	if err != nil {
 		return err
 	}
 
+	if err = tx.install(stagingDir, files); err != nil {
+		return err
+	}
+
+	for _, name := range projectFiles {
+		if err = tx.keep(name); err != nil {
+			return err
+		}
+	}
+
+	if (rewritten || p.goModSynthesized) && !p.NoVerify && !p.Offline {
+		if err = verifyModule(p.Dest, p.goModSynthesized); err != nil {
+			return err
+		}
+	}
+
 	return p.SaveToDisk()
 }
 
-func (p *Project) download() ([]byte, error) {
+// stage extracts the archive "body" to the "dir" staging directory
+// and rewrites the module path and the replacements of the extracted files.
+// It reports whether the module path was rewritten.
+func (p *Project) stage(body []byte, format, dir string) (bool, error) {
+	oldModuleName, err := p.unzip(body, format, dir)
+	if err != nil {
+		return false, err
+	}
+
+	return p.rewrite(dir, oldModuleName)
+}
+
+func (p *Project) download() ([]byte, string, error) {
 	p.Version = strings.Split(p.Version, " ")[0]
 	if p.Version == "latest" {
 		p.Version = "main"
 	}
 
-	zipURL := fmt.Sprintf("https://github.com/%s/archive/%s.zip", p.Repo, p.Version) // e.g. https://github.com/kataras/iris-cli/archive/master.zip
-	r, err := utils.DownloadReader(zipURL, nil)
+	// The whole repository is downloaded, even if only a subdirectory of it is installed.
+	repoURL, subdir := SplitSubdir(p.Repo)
+
+	if p.Git {
+		repo, err := GitRepo(repoURL)
+		if err != nil {
+			return nil, "", err
+		}
+		repoURL = repo
+		p.Repo = JoinSubdir(repoURL, subdir)
+	}
+
+	src, repo, err := ParseSource(repoURL)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	var commit string
+	if !p.Offline {
+		if resolver, ok := src.(CommitResolver); ok {
+			commit, _ = resolver.Commit(repo, p.Version) // ignore error, the archive download will report it.
+		}
+	}
+
+	if p.Cache != nil && (p.Offline || commit != "") {
+		entry, b, err := p.Cache.Get(CacheTemplate, repoURL, p.Version, commit)
+		if err == nil {
+			golog.Debugf("Cache: using <%s@%s> (%s)", repoURL, p.Version, entry.Digest)
+			p.Commit = entry.Commit
+			if err = p.verifyArchive(b); err != nil {
+				return nil, "", err
+			}
+			return b, entry.Format, nil
+		}
+
+		if err != ErrNotCached {
+			return nil, "", err
+		}
+	}
+
+	if p.Offline {
+		return nil, "", fmt.Errorf("project <%s> version <%s>: %w", repoURL, p.Version, ErrNotCached)
+	}
+
+	archive, err := src.Archive(repo, p.Version)
+	if err != nil {
+		return nil, "", err
 	}
-	defer r.Close()
+	defer archive.Body.Close()
 
+	p.Commit = archive.Commit
+	if p.Commit == "" {
+		p.Commit = commit
+	}
+
+	var b []byte
 	if p.Reader != nil {
-		return p.Reader(r)
+		b, err = p.Reader(archive.Body)
+	} else {
+		b, err = ioutil.ReadAll(archive.Body)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err = p.verifyArchive(b); err != nil {
+		return nil, "", err
+	}
+
+	if p.Cache != nil && p.staged == nil { // not on dry run.
+		entry := &CacheEntry{Kind: CacheTemplate, Repo: repoURL, Version: p.Version, Commit: p.Commit, Format: archive.Format}
+		if err = p.Cache.Put(entry, b); err != nil {
+			golog.Warnf("Cache: %v", err)
+		}
 	}
 
-	return ioutil.ReadAll(r)
+	return b, archive.Format, nil
 }
 
-func (p *Project) unzip(body []byte) error {
-	r, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
-	if err != nil {
-		return err
+// verifyArchive records the checksum of the downloaded archive "b"
+// and compares it with the pinned `ExpectedSHA256`.
+// A mismatch is an error on `Verify` mode, otherwise a warning.
+func (p *Project) verifyArchive(b []byte) error {
+	p.SHA256 = Checksum(b)
+
+	if p.ExpectedSHA256 == "" {
+		if p.Verify {
+			return fmt.Errorf("project <%s> version <%s>: %w", p.Name, p.Version, ErrChecksumMissing)
+		}
+		return nil
 	}
 
-	if len(r.File) == 0 {
-		return fmt.Errorf("empty zip")
+	if err := verifyChecksum(b, p.ExpectedSHA256); err != nil {
+		if p.Verify {
+			return fmt.Errorf("project <%s> version <%s>: %w", p.Name, p.Version, err)
+		}
+
+		golog.Warnf("Project <%s> version <%s>: %v", p.Name, p.Version, err)
 	}
 
-	first := r.File[0]
-	if !first.FileInfo().IsDir() {
-		return fmt.Errorf("expected a root folder but got <%s>", first.Name)
+	return nil
+}
+
+// unzip extracts the archive "body" to the "dir" directory, it fills the `Files`
+// and returns the module path of the archive's go.mod file.
+func (p *Project) unzip(body []byte, format, dir string) ([]byte, error) {
+	files, err := readArchive(body, format, p.MaxSize)
+	if err != nil {
+		return nil, err
 	}
 
-	if base := filepath.Base(p.Repo); !strings.Contains(first.Name, base) {
-		return fmt.Errorf("expected root folder to match the repository name <%s> but got <%s>", base, first.Name)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("empty archive")
 	}
-	compressedRootFolder := first.Name // e.g. iris-master
 
-	var oldModuleName []byte
-	// Find current module name, starting from the end because list is sorted alphabetically
-	// and "go.mod" is more likely to be visible at the end.
-	modFile := filepath.Join(compressedRootFolder, "go.mod")
-	for i := len(r.File) - 1; i > 0; i-- {
-		f := r.File[i]
-		if filepath.Clean(f.Name) == modFile {
-			rc, err := f.Open()
-			if err != nil {
-				return err
-			}
+	compressedRootFolder := archiveRoot(files) // e.g. iris-master/
+	repoRootFolder := compressedRootFolder
 
-			contents, err := ioutil.ReadAll(rc)
-			if err != nil {
-				return err
-			}
+	_, subdir := SplitSubdir(p.Repo)
+	if subdir != "" {
+		// Install only the subtree, e.g. iris-master/_examples/mvc/.
+		compressedRootFolder += subdir + "/"
 
-			oldModuleName = parser.ModulePath(contents)
-			if p.Module == "" {
-				// if new module name is empty, then default it to the remote one.
-				p.Module = string(oldModuleName)
+		found := false
+		for _, f := range files {
+			if strings.HasPrefix(f.Name, compressedRootFolder) {
+				found = true
+				break
 			}
+		}
 
-			break
+		if !found {
+			return nil, fmt.Errorf("project <%s> version <%s>: subdirectory <%s> does not exist", p.Name, p.Version, subdir)
 		}
 	}
 
-	if len(oldModuleName) == 0 {
-		// no go mod found, stop here  as we dont' support non-go modules, Iris depends on go 1.13.
-		return fmt.Errorf("project <%s> version <%s> is not a go module, please try other version", p.Name, p.Version)
+	// Find current module name, the nearest go.mod of the subdirectory.
+	mod, err := findModule(files, repoRootFolder, subdir)
+	if err != nil {
+		return nil, err
 	}
 
 	var (
-		newModuleName = []byte(p.Module)
+		oldModuleName []byte
+		synthesizeMod bool
 	)
 
-	p.Dest = utils.Dest(p.Dest)
+	if mod != nil {
+		oldModuleName = []byte(mod.Path)
+		synthesizeMod = mod.Dir != subdir
+	} else if subdir != "" {
+		// No go.mod at all, the new module name is used as it is.
+		synthesizeMod = true
+		oldModuleName = []byte(p.Module)
+		if len(oldModuleName) == 0 {
+			oldModuleName = []byte(path.Base(subdir))
+		}
+	}
 
-	for _, f := range r.File {
-		rel, err := filepath.Rel(compressedRootFolder, f.Name)
-		if err != nil {
+	if p.Module == "" {
+		// if new module name is empty, then default it to the remote one.
+		p.Module = string(oldModuleName)
+	}
+
+	if len(oldModuleName) == 0 {
+		// no go mod found, stop here  as we dont' support non-go modules, Iris depends on go 1.13.
+		return nil, fmt.Errorf("project <%s> version <%s> is not a go module, please try other version", p.Name, p.Version)
+	}
+
+	tmpl, err := p.loadTemplate(files, compressedRootFolder)
+	if err != nil {
+		return nil, err
+	}
+
+	e := newExtractor(dir, p.MaxSize, p.MaxFiles)
+	e.staged = p.staged
+
+	for _, f := range files {
+		if !strings.HasPrefix(f.Name, compressedRootFolder) {
 			continue
 		}
 
-		if rel == "." {
+		name := strings.TrimSuffix(strings.TrimPrefix(f.Name, compressedRootFolder), "/")
+		if name == "" {
 			continue
 		}
 
-		name := filepath.ToSlash(rel)
-		fpath := path.Join(p.Dest, name)
+		if tmpl != nil {
+			if name == TemplateFilename {
+				continue
+			}
 
-		// // https://snyk.io/research/zip-slip-vulnerability#go
-		// if !strings.HasPrefix(fpath, p.Dest+"/") {
-		// 	return fmt.Errorf("illegal path: %s", fpath)
-		// }
+			var ok bool
+			if name, ok, err = tmpl.RenderName(name, p.Answers); err != nil {
+				return nil, err
+			} else if !ok {
+				continue
+			}
+
+			if ok, err = tmpl.Include(name, p.Answers); err != nil {
+				return nil, err
+			} else if !ok {
+				continue
+			}
+		}
+
+		if err = e.add(name); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case f.IsDir():
+			if err = e.mkdir(name, f.Mode); err != nil {
+				return nil, err
+			}
 
-		if f.FileInfo().IsDir() {
 			p.Files = append(p.Files, name)
+			continue
+		case f.Hardlink:
+			return nil, &ExtractError{Name: name, Err: ErrSymlink}
+		case f.Mode&os.ModeSymlink != 0:
+			target := f.Link
+			if target == "" { // zip.
+				rc, err := f.Open()
+				if err != nil {
+					return nil, err
+				}
 
-			if err := os.MkdirAll(fpath, os.ModePerm); err != nil {
-				return err
+				b, err := e.read(name, rc)
+				rc.Close()
+				if err != nil {
+					return nil, err
+				}
+				target = string(b)
+			}
+
+			if err = e.symlink(name, target); err != nil {
+				return nil, err
 			}
+
+			p.Files = append(p.Files, name)
 			continue
+		case !f.Mode.IsRegular():
+			return nil, &ExtractError{Name: name, Err: ErrUnsupportedEntry}
 		}
 
-		outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+		rc, err := f.Open()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		rc, err := f.Open()
+		contents, err := e.read(name, rc)
+		rc.Close()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		// If new(local) module name differs the current(remote) one.
-		if shouldReplaceModule, hasReplacements := !bytes.Equal(oldModuleName, newModuleName), len(p.Replacements) > 0; shouldReplaceModule || hasReplacements {
-			contents, ioErr := ioutil.ReadAll(rc)
-			if ioErr != nil {
-				return ioErr
+		if tmpl != nil {
+			if contents, err = tmpl.Render(name, contents, p.Answers); err != nil {
+				return nil, err
 			}
+		}
 
-			if shouldReplaceModule {
-				contents = bytes.ReplaceAll(contents, oldModuleName, newModuleName)
+		if err = e.writeFile(name, contents, f.Mode); err != nil {
+			return nil, err
+		}
+
+		p.Files = append(p.Files, name)
+	}
+
+	if synthesizeMod {
+		// The subdirectory is not a module by itself.
+		b, err := synthesizeGoMod(mod, string(oldModuleName), p.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		generated := map[string][]byte{"go.mod": b}
+		if mod != nil && len(mod.SumFile) > 0 {
+			generated["go.sum"] = mod.SumFile
+		}
+
+		for _, name := range []string{"go.mod", "go.sum"} {
+			contents, ok := generated[name]
+			if !ok || containsString(p.Files, name) {
+				continue
 			}
 
-			if hasReplacements {
-				for oldContent, newContent := range p.Replacements {
-					if !shouldReplaceModule {
-						// If username/repo style then update go module too.
-						if key := "github.com/" + oldContent; key == p.Module {
-							newModuleName = append([]byte("github.com/"), newContent...)
-							contents = bytes.ReplaceAll(contents, oldModuleName, newModuleName)
-							p.Module = string(newModuleName)
-							shouldReplaceModule = true // once.
-						}
-					}
+			if err = e.add(name); err != nil {
+				return nil, err
+			}
 
-					contents = bytes.ReplaceAll(contents, []byte(oldContent), []byte(newContent))
-				}
+			if err = e.writeFile(name, contents, 0644); err != nil {
+				return nil, err
 			}
 
-			_, err = outFile.Write(contents)
-		} else {
-			_, err = io.Copy(outFile, rc)
+			p.Files = append(p.Files, name)
 		}
 
-		outFile.Close()
-		rc.Close()
+		p.goModSynthesized = true
+	}
+
+	return oldModuleName, nil
+}
+
+// rewrite replaces the module path and the `Replacements` of the installed files inside "dir".
+func (p *Project) rewrite(dir string, oldModuleName []byte) (bool, error) {
+	rewriteFile, moduleRewritten := p.rewriter(oldModuleName)
+	if rewriteFile == nil {
+		return false, nil
+	}
+
+	for _, name := range p.Files {
+		if p.staged != nil { // dry run.
+			f, ok := p.staged[name]
+			if !ok || !f.Mode.IsRegular() {
+				continue
+			}
 
+			newContents, err := rewriteFile(name, f.Contents)
+			if err != nil {
+				return false, err
+			}
+
+			if !bytes.Equal(f.Contents, newContents) {
+				f.Original, f.Contents = f.Contents, newContents
+			}
+			continue
+		}
+
+		fpath := filepath.Join(dir, filepath.FromSlash(name))
+		info, err := os.Lstat(fpath)
 		if err != nil {
-			return err
+			return false, err
 		}
 
-		// if err = os.Chtimes(fpath, modTime, modTime); err != nil {
-		// 	return err
-		// }
+		if !info.Mode().IsRegular() {
+			continue
+		}
 
-		p.Files = append(p.Files, name)
+		contents, err := ioutil.ReadFile(fpath)
+		if err != nil {
+			return false, err
+		}
+
+		newContents, err := rewriteFile(name, contents)
+		if err != nil {
+			return false, err
+		}
+
+		if bytes.Equal(contents, newContents) {
+			continue
+		}
+
+		if err = ioutil.WriteFile(fpath, newContents, info.Mode().Perm()); err != nil {
+			return false, err
+		}
 	}
 
-	return nil
+	return moduleRewritten, nil
+}
+
+// rewriter returns a function which replaces the module path and the `Replacements`
+// of a file's contents, or nil if there is nothing to replace.
+// It reports whether the module path is replaced.
+func (p *Project) rewriter(oldModuleName []byte) (func(name string, contents []byte) ([]byte, error), bool) {
+	newModuleName := []byte(p.Module)
+	shouldReplaceModule := !bytes.Equal(oldModuleName, newModuleName)
+
+	if !shouldReplaceModule {
+		for oldContent, newContent := range p.Replacements {
+			// If username/repo style then update go module too.
+			if key := "github.com/" + oldContent; key == p.Module {
+				newModuleName = append([]byte("github.com/"), newContent...)
+				p.Module = string(newModuleName)
+				shouldReplaceModule = true
+				break
+			}
+		}
+	}
+
+	// If new(local) module name differs the current(remote) one.
+	if !shouldReplaceModule && len(p.Replacements) == 0 {
+		return nil, false
+	}
+
+	m := &moduleRewriter{oldPath: string(oldModuleName), newPath: string(newModuleName)}
+	rewriteFiles := p.RewriteFiles
+	if rewriteFiles == nil {
+		rewriteFiles = DefaultRewriteFiles
+	}
+
+	return func(name string, contents []byte) ([]byte, error) {
+		newContents := contents
+		if shouldReplaceModule {
+			switch {
+			case path.Base(name) == "go.mod":
+				b, ok, err := m.rewriteGoMod(name, newContents)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					newContents = b
+				}
+			case strings.HasSuffix(name, ".go"):
+				if b, ok := m.rewriteGoFile(name, newContents); ok {
+					newContents = b
+				}
+			case shouldRewriteText(rewriteFiles, name):
+				newContents = bytes.ReplaceAll(newContents, oldModuleName, newModuleName)
+			}
+		}
+
+		if !isBinary(newContents) {
+			for oldContent, newContent := range p.Replacements {
+				newContents = bytes.ReplaceAll(newContents, []byte(oldContent), []byte(newContent))
+			}
+		}
+
+		return newContents, nil
+	}, shouldReplaceModule
 }
 
 func (p *Project) Run(stdout, stderr io.Writer) error {
+	if err := p.loadEnv(); err != nil {
+		return err
+	}
+
 	utils.RegisterOnInterrupt(p.onTerminate)
 
 	p.stdout = stdout
@@ -404,14 +804,14 @@ func (p *Project) onTerminate() {
 
 	if p.Running {
 		p.Running = false
-		p.SaveToDisk()
+		p.SaveState()
 	}
 }
 
 func (p *Project) run() (err error) {
 	// catch build or run errors and set running to false if errored on Run (with or without watch).
 	p.Running = true
-	if err = p.SaveToDisk(); err != nil {
+	if err = p.SaveState(); err != nil {
 		return
 	}
 
@@ -427,6 +827,7 @@ func (p *Project) run() (err error) {
 
 func (p *Project) start() error {
 	if runCmd := getActionCommand(p.Dest, ActionRun); runCmd != nil {
+		runCmd.Env = p.env
 		runCmd.Dir = p.Dest
 		runCmd.Stdout = p.stdout
 		runCmd.Stderr = p.stderr
@@ -439,14 +840,19 @@ func (p *Project) start() error {
 	}
 
 	bin := utils.FormatExecutable(filepath.Base(p.Dest))
-	buildCmd := utils.Command("go", "build", "-o", bin, ".")
+	buildArgs := []string{"build"}
+	if tags := p.buildTags(); len(tags) > 0 {
+		buildArgs = append(buildArgs, "-tags", strings.Join(tags, ","))
+	}
+	buildCmd := utils.Command("go", append(buildArgs, "-o", bin, ".")...)
+	buildCmd.Env = p.env
 	buildCmd.Dir = p.Dest
 
 	if b, err := buildCmd.CombinedOutput(); err != nil {
 		return errors.New(string(b)) // don't use fmt.Errorf here for any case that the format contains vars.
 	}
 
-	runCmd, err := utils.StartExecutable(p.Dest, bin, p.stdout, p.stderr)
+	runCmd, err := utils.StartExecutable(p.Dest, bin, p.env, p.stdout, p.stderr)
 	if err != nil {
 		return err
 	}
@@ -485,6 +891,9 @@ func (p *Project) build() error {
 		for evts := range watcher.Events {
 			for _, evt := range evts {
 				name := p.rel(evt.Name)
+				if isProjectFile(name) {
+					continue
+				}
 
 				// fmt.Printf("| %s | %s\n", evt.Op.String(), name)
 
@@ -516,7 +925,7 @@ func (p *Project) build() error {
 		}
 	}()
 
-	defer p.SaveToDisk()
+	defer p.SaveState()
 	defer watcher.Close()
 
 	// newFilesFn, err := utils.GetFilesDiff(p.Dest)
@@ -527,6 +936,7 @@ func (p *Project) build() error {
 	// Try to build with "make", "nmake" or "build.bat", "build.sh".
 	buildCmd := getActionCommand(p.Dest, ActionBuild)
 	if buildCmd != nil {
+		buildCmd.Env = p.env
 		return runCmd(buildCmd, p.Dest)
 
 		// if buildFiles := newFilesFn(); len(buildFiles) > 0 {
@@ -550,8 +960,8 @@ func (p *Project) build() error {
 	npmBin, err := exec.LookPath(p.NodePackageManager)
 	if err != nil {
 		return fmt.Errorf(
-			"nodePackageManager in .iris.yml is set to %s, but %s could not be found",
-			p.NodePackageManager, p.NodePackageManager)
+			"NodePackageManager in %s is set to %s, but %s could not be found, install it or run: iris-cli config set node_package_manager <name>",
+			p.ConfigFile(), p.NodePackageManager, p.NodePackageManager)
 	}
 
 	for _, f := range files {
@@ -583,6 +993,7 @@ func (p *Project) build() error {
 
 			if shouldNpmInstall {
 				installCmd, cancelFunc := utils.CommandWithCancel(npmBin, "install")
+				installCmd.Env = p.env
 				p.frontEndRunningCommands[installCmd] = cancelFunc
 				// defer cancelFunc()
 				if err = runCmd(installCmd, dir); err != nil {
@@ -600,6 +1011,7 @@ func (p *Project) build() error {
 
 			if _, ok := v.Scripts[ActionBuild]; ok {
 				buildCmd, cancelFunc := utils.CommandWithCancel(npmBin, "run", ActionBuild)
+				buildCmd.Env = p.env
 				p.frontEndRunningCommands[buildCmd] = cancelFunc
 				// defer cancelFunc()
 				if err = runCmd(buildCmd, dir); err != nil {
@@ -618,6 +1030,7 @@ func (p *Project) build() error {
 		if !p.DisableInlineCommands {
 			for _, c := range res.Commands {
 				cmd, cancelFunc := utils.CommandWithCancel(c.Name, c.Args...)
+				cmd.Env = p.env
 				// Author's Note:
 				// track the executed commands: if go-bindata related
 				// with the same res.AssetDirs[x] then skip the manual go-bindata command execution
@@ -664,6 +1077,7 @@ func (p *Project) build() error {
 				"bindata.go",
 			}, dirsToBuild...)
 			goBindata, cancelFunc := utils.CommandWithCancel("go-bindata", args...)
+			goBindata.Env = p.env
 			p.frontEndRunningCommands[goBindata] = cancelFunc
 			// defer cancelFunc()
 			if err = runCmd(goBindata, p.Dest); err != nil {
@@ -781,6 +1195,12 @@ func (p *Project) watch() error {
 		}
 
 		if backend {
+			// Keep the running backend if the changed environment files cannot be loaded.
+			if err = p.loadEnv(); err != nil {
+				golog.Error(err)
+				return
+			}
+
 			p.killBackendProcesses()
 			// timeout := time.Second // give some time to release the TCP server port.
 			// for conn, _ := net.DialTimeout("tcp", ":8080", timeout); conn != nil; {
@@ -827,7 +1247,7 @@ func (p *Project) watch() error {
 				*/
 				// OR
 				// 3. let iris tell us what it's port by creating a temp file in the current working directory
-				// or by changing the .iris.yml configuration file itself to a Running: Port: $PORT and then
+				// or by changing the .iris/state.yml file itself to a Running: Port: $PORT and then
 				// let live reloader read it and send it to the client side of the app.
 				//
 				// Maybe browser live reload on backend addr/port changing does not worth such a waste of time.
@@ -855,7 +1275,12 @@ func (p *Project) watch() error {
 			for _, evt := range evts {
 				name := p.rel(evt.Name)
 
-				if name == ProjectFilename {
+				if isProjectFile(name) {
+					continue
+				}
+
+				if p.isEnvFile(name) {
+					backendChanged = true
 					continue
 				}
 
@@ -907,7 +1332,7 @@ func (p *Project) Clean() (err error) {
 	}
 
 	p.BuildFiles = nil
-	return p.SaveToDisk()
+	return p.SaveState()
 }
 
 // Unistall removes all project-associated files.
@@ -931,9 +1356,13 @@ func (p *Project) Unistall() (err error) {
 	binFile := filepath.Join(p.Dest, utils.FormatExecutable(filepath.Base(p.Dest)))
 	os.Remove(binFile)
 
-	// remove project file too.
-	projectFile := filepath.Join(p.Dest, ProjectFilename)
-	return os.Remove(projectFile)
+	// remove project files too.
+	os.Remove(filepath.Join(p.Dest, LegacyProjectFilename)) // ignore error.
+	if err = os.RemoveAll(filepath.Join(p.Dest, StateDir)); err != nil {
+		return
+	}
+
+	return os.Remove(filepath.Join(p.Dest, p.ConfigFile()))
 }
 
 const (
@@ -1013,7 +1442,9 @@ func runCmd(cmd *exec.Cmd, dir string) error {
 				args = cmd.Args[1:]
 			}
 
-			return runCmd(utils.Command(name, args...), cmd.Dir)
+			retryCmd := utils.Command(name, args...)
+			retryCmd.Env = cmd.Env
+			return runCmd(retryCmd, cmd.Dir)
 		}
 	}
 
@@ -0,0 +1,122 @@
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kataras/iris-cli/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFilenames are the supported project configuration files, one per format: YAML, JSON and TOML.
+// The format of a project is detected by whichever of them exists, see `LoadFromDisk`.
+var ProjectFilenames = []string{ProjectFilename, "iris.json", "iris.toml"}
+
+// ProjectFormats are the formats of the `ProjectFilenames`, see `Project.Convert`.
+var ProjectFormats = []string{"yml", "json", "toml"}
+
+// projectFilename returns the project file of a "format", e.g. "toml".
+func projectFilename(format string) (string, error) {
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+	if format == "yaml" {
+		format = "yml"
+	}
+
+	for i, f := range ProjectFormats {
+		if f == format {
+			return ProjectFilenames[i], nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown project file format: %s, expected one of: %s", format, strings.Join(ProjectFormats, ", "))
+}
+
+// findProjectFile returns the project file of the "projectPath" directory or empty if it does not exist.
+// It returns an error if more than one of the `ProjectFilenames` exist.
+func findProjectFile(projectPath string) (string, error) {
+	var found []string
+	for _, name := range ProjectFilenames {
+		if utils.Exists(filepath.Join(projectPath, name)) {
+			found = append(found, name)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", nil
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("more than one project file: %s, keep one of them", strings.Join(found, ", "))
+	}
+}
+
+// ConfigFile returns the project's configuration file, one of the `ProjectFilenames`.
+// Defaults to the `ProjectFilename`.
+func (p *Project) ConfigFile() string {
+	if p.configFile == "" {
+		return ProjectFilename
+	}
+
+	return p.configFile
+}
+
+// Convert rewrites the project's configuration file in the "format", one of the `ProjectFormats`,
+// and removes the previous one. The written file is read back and compared with the project's
+// configuration, so the conversion fails instead of losing any settings.
+// It returns the new file's name.
+func (p *Project) Convert(format string) (string, error) {
+	name, err := projectFilename(format)
+	if err != nil {
+		return "", err
+	}
+
+	oldName := p.ConfigFile()
+	if name == oldName {
+		return name, nil
+	}
+
+	file := filepath.Join(p.Dest, name)
+	if utils.Exists(file) {
+		return "", fmt.Errorf("project file <%s> already exists", name)
+	}
+
+	if err = utils.Export(file, p); err != nil {
+		os.Remove(file)
+		return "", err
+	}
+
+	converted := new(Project)
+	if _, err = readProjectFile(file, converted); err != nil {
+		os.Remove(file)
+		return "", err
+	}
+
+	expected, err := yaml.Marshal(p)
+	if err != nil {
+		os.Remove(file)
+		return "", err
+	}
+
+	got, err := yaml.Marshal(converted)
+	if err != nil {
+		os.Remove(file)
+		return "", err
+	}
+
+	if !bytes.Equal(expected, got) {
+		os.Remove(file)
+		return "", fmt.Errorf("project file <%s> cannot be converted to %s without losing settings:\n%s", oldName, format, unifiedDiff(name, expected, got))
+	}
+
+	if err = os.Remove(filepath.Join(p.Dest, oldName)); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	p.configFile = name
+	return name, nil
+}
@@ -0,0 +1,508 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectIssue is a problem of a project file, see `ProjectFileError`.
+type ProjectIssue struct {
+	// Line is the line of the problem in the project file, zero if unknown.
+	Line int
+	// Field is the dotted path of the invalid value, e.g. Watcher.Backend[0].
+	// Empty for decoding errors.
+	Field string
+	Err   error
+}
+
+// ProjectFileError is returned by `LoadFromDisk` when the project file contains
+// unknown fields, it cannot be decoded or its values are invalid.
+type ProjectFileError struct {
+	// File is the name of the project file, e.g. iris.yml.
+	File   string
+	Issues []*ProjectIssue
+}
+
+func (e *ProjectFileError) Error() string {
+	lines := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		msg := issue.Err.Error()
+		if issue.Field != "" {
+			msg = issue.Field + ": " + msg
+		}
+
+		if issue.Line > 0 {
+			lines = append(lines, fmt.Sprintf("%s:%d: %s", e.File, issue.Line, msg))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s: %s", e.File, msg))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// projectFileFormat returns the format of a project "file", one of the `ProjectFormats`.
+func projectFileFormat(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		return "json"
+	case ".toml":
+		return "toml"
+	default:
+		return "yml"
+	}
+}
+
+// formatTag returns the struct tag of a project file's "format".
+func formatTag(format string) string {
+	if format == "yml" {
+		return "yaml"
+	}
+
+	return format
+}
+
+// readProjectFile decodes the project's configuration "file" to "p" and returns its contents.
+// Unknown fields are errors, so typos are not silently ignored.
+func readProjectFile(file string, p *Project) ([]byte, error) {
+	body, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	format := projectFileFormat(file)
+	switch format {
+	case "json":
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(p)
+	case "toml":
+		dec := toml.NewDecoder(bytes.NewReader(body))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(p)
+	default:
+		dec := yaml.NewDecoder(bytes.NewReader(body))
+		dec.KnownFields(true)
+		err = dec.Decode(p)
+	}
+
+	if err != nil && err != io.EOF {
+		return nil, &ProjectFileError{File: filepath.Base(file), Issues: decodeIssues(body, format, err)}
+	}
+
+	// JSON numbers are decoded as floats.
+	for name, v := range p.Answers {
+		if f, ok := v.(float64); ok && f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			p.Answers[name] = int64(f)
+		}
+	}
+
+	return body, nil
+}
+
+var (
+	yamlErrorLine       = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	yamlUnknownField    = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+	jsonUnknownFieldErr = regexp.MustCompile(`^json: unknown field "(.*)"$`)
+)
+
+// decodeIssues converts a decoding error of a project file's "body" to issues with line numbers.
+func decodeIssues(body []byte, format string, err error) []*ProjectIssue {
+	var (
+		yamlTypeErr   *yaml.TypeError
+		jsonSyntaxErr *json.SyntaxError
+		jsonTypeErr   *json.UnmarshalTypeError
+		tomlStrictErr *toml.StrictMissingError
+		tomlDecodeErr *toml.DecodeError
+	)
+
+	switch {
+	case errors.As(err, &yamlTypeErr):
+		issues := make([]*ProjectIssue, 0, len(yamlTypeErr.Errors))
+		for _, msg := range yamlTypeErr.Errors {
+			issues = append(issues, yamlIssue(msg))
+		}
+		return issues
+	case errors.As(err, &jsonSyntaxErr):
+		return []*ProjectIssue{{Line: lineAt(body, jsonSyntaxErr.Offset), Err: errors.New(strings.TrimPrefix(err.Error(), "json: "))}}
+	case errors.As(err, &jsonTypeErr):
+		return []*ProjectIssue{{Line: lineAt(body, jsonTypeErr.Offset), Field: jsonTypeErr.Field, Err: fmt.Errorf("cannot decode %s as %s", jsonTypeErr.Value, jsonTypeErr.Type)}}
+	case errors.As(err, &tomlStrictErr):
+		issues := make([]*ProjectIssue, 0, len(tomlStrictErr.Errors))
+		for _, e := range tomlStrictErr.Errors {
+			line, _ := e.Position()
+			issues = append(issues, &ProjectIssue{Line: line, Err: fmt.Errorf("unknown field: %s", strings.Join(e.Key(), "."))})
+		}
+		return issues
+	case errors.As(err, &tomlDecodeErr):
+		line, _ := tomlDecodeErr.Position()
+		return []*ProjectIssue{{Line: line, Err: errors.New(strings.TrimPrefix(tomlDecodeErr.Error(), "toml: "))}}
+	}
+
+	if format == "yml" {
+		return []*ProjectIssue{yamlIssue(err.Error())}
+	}
+
+	if m := jsonUnknownFieldErr.FindStringSubmatch(err.Error()); m != nil {
+		line := 0
+		if loc := regexp.MustCompile(`"` + regexp.QuoteMeta(m[1]) + `"\s*:`).FindIndex(body); loc != nil {
+			line = lineAt(body, int64(loc[0]))
+		}
+		return []*ProjectIssue{{Line: line, Err: fmt.Errorf("unknown field: %s", m[1])}}
+	}
+
+	return []*ProjectIssue{{Err: err}}
+}
+
+// yamlIssue parses a YAML decoding error message, e.g. "line 3: field Backnd not found in type project.Watcher".
+func yamlIssue(msg string) *ProjectIssue {
+	issue := new(ProjectIssue)
+	if m := yamlErrorLine.FindStringSubmatch(msg); m != nil {
+		issue.Line, _ = strconv.Atoi(m[1])
+		msg = m[2]
+	}
+
+	if m := yamlUnknownField.FindStringSubmatch(msg); m != nil {
+		issue.Err = fmt.Errorf("unknown field: %s", m[1])
+	} else {
+		issue.Err = errors.New(strings.TrimPrefix(msg, "yaml: "))
+	}
+
+	return issue
+}
+
+// lineAt returns the line, starting at 1, of the byte "offset" of "body".
+func lineAt(body []byte, offset int64) int {
+	if offset > int64(len(body)) {
+		offset = int64(len(body))
+	}
+
+	return bytes.Count(body[:offset], []byte{'\n'}) + 1
+}
+
+// fieldPath is the path of a project's value: struct field names, map keys and slice indexes.
+type fieldPath []interface{}
+
+func (f fieldPath) field(name string) fieldPath {
+	return append(f[:len(f):len(f)], name)
+}
+
+func (f fieldPath) index(i int) fieldPath {
+	return append(f[:len(f):len(f)], i)
+}
+
+// encode returns the path with the struct field names of the "typ" encoded by the "tag", e.g. "yaml".
+func (f fieldPath) encode(typ reflect.Type, tag string) fieldPath {
+	encoded := make(fieldPath, 0, len(f))
+	for _, elem := range f {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		switch typ.Kind() {
+		case reflect.Struct:
+			name := elem.(string)
+			encoded = append(encoded, fieldName(typ, name, tag))
+			if field, ok := typ.FieldByName(name); ok {
+				typ = field.Type
+			}
+		case reflect.Map, reflect.Slice:
+			typ = typ.Elem()
+			encoded = append(encoded, elem)
+		default:
+			encoded = append(encoded, elem)
+		}
+	}
+
+	return encoded
+}
+
+func (f fieldPath) String() string {
+	var b strings.Builder
+	for _, elem := range f {
+		switch v := elem.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%d]", v)
+		default:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			fmt.Fprint(&b, v)
+		}
+	}
+
+	return b.String()
+}
+
+// invalidField is a value of a project which failed the validation.
+type invalidField struct {
+	path fieldPath
+	err  error
+}
+
+var buildTagPattern = regexp.MustCompile(`^[\w.]+$`)
+
+// invalidFields reports the invalid values of the project's configuration.
+func (p *Project) invalidFields() []invalidField {
+	var fields []invalidField
+	addField := func(path fieldPath, format string, args ...interface{}) {
+		fields = append(fields, invalidField{path: path, err: fmt.Errorf(format, args...)})
+	}
+
+	watcher := fieldPath{"Watcher"}
+	for _, name := range []string{"Backend", "Frontend"} {
+		extensions := p.Watcher.Backend
+		if name == "Frontend" {
+			extensions = p.Watcher.Frontend
+		}
+
+		for i, ext := range extensions {
+			if !strings.HasPrefix(ext, ".") || len(ext) < 2 || strings.ContainsAny(ext, `/\ `) {
+				addField(watcher.field(name).index(i), "invalid file extension: %q, expected a dot followed by the extension, e.g. .go", ext)
+			}
+		}
+	}
+
+	for i, pattern := range p.Watcher.IgnoreDirs {
+		if _, err := path.Match(pattern, ""); err != nil {
+			addField(watcher.field("IgnoreDirs").index(i), "invalid pattern: %q: %v", pattern, err)
+		}
+	}
+
+	if p.LiveReload != nil && (p.LiveReload.Port < 0 || p.LiveReload.Port > math.MaxUint16) {
+		addField(fieldPath{"LiveReload", "Port"}, "port %d out of range, expected 0-%d", p.LiveReload.Port, math.MaxUint16)
+	}
+
+	names := make([]string, 0, len(p.Profiles))
+	for name := range p.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		profile := p.Profiles[name]
+		if profile == nil {
+			continue
+		}
+
+		for i, tag := range profile.Tags {
+			if !buildTagPattern.MatchString(tag) {
+				addField(fieldPath{"Profiles", name, "Tags"}.index(i), "invalid build tag: %q", tag)
+			}
+		}
+	}
+
+	return fields
+}
+
+// validate checks the values of the project's configuration, its "body" is used to report the lines.
+func (p *Project) validate(body []byte) error {
+	fields := p.invalidFields()
+	if len(fields) == 0 {
+		return nil
+	}
+
+	format := projectFileFormat(p.ConfigFile())
+	tag := formatTag(format)
+
+	var lines map[string]int
+	if format == "toml" {
+		lines = tomlKeyLines(body)
+	}
+
+	issues := make([]*ProjectIssue, 0, len(fields))
+	for _, f := range fields {
+		path := f.path.encode(reflect.TypeOf(Project{}), tag)
+
+		var line int
+		switch format {
+		case "json":
+			line = jsonLine(body, path)
+		case "toml":
+			line = tomlLine(lines, path)
+		default:
+			line = yamlLine(body, path)
+		}
+
+		issues = append(issues, &ProjectIssue{Line: line, Field: path.String(), Err: f.err})
+	}
+
+	return &ProjectFileError{File: p.ConfigFile(), Issues: issues}
+}
+
+// yamlLine returns the line of the value of the encoded "path" or of its closest parent, zero if not found.
+func yamlLine(body []byte, path fieldPath) int {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(body, &doc); err != nil || len(doc.Content) == 0 {
+		return 0
+	}
+
+	node, line := doc.Content[0], 0
+	for _, elem := range path {
+		var next *yaml.Node
+		switch key := elem.(type) {
+		case string:
+			if node.Kind != yaml.MappingNode {
+				return line
+			}
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case int:
+			if node.Kind == yaml.SequenceNode && key < len(node.Content) {
+				next = node.Content[key]
+			}
+		}
+
+		if next == nil {
+			return line
+		}
+		node, line = next, next.Line
+	}
+
+	return line
+}
+
+// jsonLine returns the line of the value of the encoded "path" or of its closest parent, zero if not found.
+// Keys are matched case-insensitively, as the encoding/json package does.
+func jsonLine(body []byte, path fieldPath) int {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	line := 0
+
+	for _, elem := range path {
+		t, err := dec.Token()
+		if err != nil {
+			return line
+		}
+
+		found := false
+		switch key := elem.(type) {
+		case string:
+			if t != json.Delim('{') {
+				return line
+			}
+
+			for dec.More() {
+				t, err := dec.Token()
+				if err != nil {
+					return line
+				}
+
+				if name, _ := t.(string); strings.EqualFold(name, key) {
+					found = true
+					line = lineAt(body, dec.InputOffset())
+					break
+				}
+
+				var skip json.RawMessage
+				if err = dec.Decode(&skip); err != nil {
+					return line
+				}
+			}
+		case int:
+			if t != json.Delim('[') {
+				return line
+			}
+
+			for i := 0; i <= key && dec.More(); i++ {
+				if i == key {
+					found = true
+					offset := dec.InputOffset()
+					for offset < int64(len(body)) && strings.IndexByte(", \t\r\n", body[offset]) >= 0 {
+						offset++
+					}
+					line = lineAt(body, offset)
+					break
+				}
+
+				var skip json.RawMessage
+				if err = dec.Decode(&skip); err != nil {
+					return line
+				}
+			}
+		}
+
+		if !found {
+			return line
+		}
+	}
+
+	return line
+}
+
+// tomlKeyLines returns the lines of the keys and tables of a TOML "body", by their encoded paths.
+func tomlKeyLines(body []byte) map[string]int {
+	lines := make(map[string]int)
+
+	var p unstable.Parser
+	p.Reset(body)
+
+	var table []string
+	for p.NextExpression() {
+		expr := p.Expression()
+		switch expr.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			table = table[:0]
+			it := expr.Key()
+			for it.Next() {
+				table = append(table, string(it.Node().Data))
+				if expr.Kind == unstable.Table {
+					lines[strings.Join(table, "\x00")] = p.Shape(it.Node().Raw).Start.Line
+				}
+			}
+		case unstable.KeyValue:
+			key := append([]string(nil), table...)
+			it := expr.Key()
+			line := 0
+			for it.Next() {
+				if line == 0 {
+					line = p.Shape(it.Node().Raw).Start.Line
+				}
+				key = append(key, string(it.Node().Data))
+				lines[strings.Join(key, "\x00")] = line
+			}
+		}
+	}
+
+	return lines
+}
+
+// tomlLine returns the line of the encoded "path" or of its closest parent, zero if not found.
+// The elements of the arrays are reported at their key's line.
+func tomlLine(lines map[string]int, path fieldPath) int {
+	var key []string
+	for _, elem := range path {
+		name, ok := elem.(string)
+		if !ok {
+			break
+		}
+		key = append(key, name)
+	}
+
+	for ; len(key) > 0; key = key[:len(key)-1] {
+		if line, ok := lines[strings.Join(key, "\x00")]; ok {
+			return line
+		}
+	}
+
+	return 0
+}
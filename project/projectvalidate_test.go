@@ -0,0 +1,174 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestLoadFromDiskValidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name     string
+		contents string
+		expected []string
+	}{
+		{
+			name: "iris.yml",
+			contents: `Name: app
+Watcher:
+  Backnd: [.go]
+`,
+			expected: []string{"iris.yml:3: unknown field: Backnd"},
+		},
+		{
+			name: "iris.yml",
+			contents: `Name: app
+Watcher:
+  Frontend:
+    - .html
+    - js
+LiveReload:
+  Port: 70000
+Profiles:
+  dev:
+    Tags: [a b]
+`,
+			expected: []string{
+				`iris.yml:5: Watcher.Frontend[1]: invalid file extension: "js", expected a dot followed by the extension, e.g. .go`,
+				"iris.yml:7: LiveReload.Port: port 70000 out of range, expected 0-65535",
+				`iris.yml:10: Profiles.dev.Tags[0]: invalid build tag: "a b"`,
+			},
+		},
+		{
+			name: "iris.json",
+			contents: `{
+  "name": "app",
+  "watcher": {
+    "backnd": [".go"]
+  }
+}`,
+			expected: []string{"iris.json:4: unknown field: backnd"},
+		},
+		{
+			name: "iris.json",
+			contents: `{
+  "watcher": {
+    "backend": [
+      ".go",
+      "go"
+    ]
+  },
+  "livereload": {"port": -1}
+}`,
+			expected: []string{
+				`iris.json:5: watcher.backend[1]: invalid file extension: "go", expected a dot followed by the extension, e.g. .go`,
+				"iris.json:8: livereload.port: port -1 out of range, expected 0-65535",
+			},
+		},
+		{
+			name: "iris.toml",
+			contents: `Name = "app"
+
+[Watcher]
+backnd = [".go"]
+`,
+			expected: []string{"iris.toml:4: unknown field: Watcher.backnd"},
+		},
+		{
+			name: "iris.toml",
+			contents: `Name = "app"
+
+[LiveReload]
+Port = 70000
+`,
+			expected: []string{"iris.toml:4: LiveReload.Port: port 70000 out of range, expected 0-65535"},
+		},
+	}
+
+	for i, tt := range tests {
+		projectPath := filepath.Join(dir, strconv.Itoa(i))
+		writeTestFile(t, filepath.Join(projectPath, tt.name), tt.contents)
+
+		_, err := LoadFromDisk(projectPath)
+
+		var fileErr *ProjectFileError
+		if !errors.As(err, &fileErr) {
+			t.Fatalf("[%d] expected a project file error but got: %v", i, err)
+		}
+
+		var got []string
+		for _, issue := range fileErr.Issues {
+			got = append(got, (&ProjectFileError{File: fileErr.File, Issues: []*ProjectIssue{issue}}).Error())
+		}
+
+		if !reflect.DeepEqual(tt.expected, got) {
+			t.Fatalf("[%d] expected issues:\n%q\nbut got:\n%q", i, tt.expected, got)
+		}
+	}
+}
+
+func TestLoadFromDiskNodePackageManager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The node package manager is resolved on Run, a machine without it can still load and edit the project.
+	writeTestFile(t, filepath.Join(dir, ProjectFilename), "Name: app\nNodePackageManager: iris-cli-missing-npm\n")
+	writeTestFile(t, filepath.Join(dir, "package.json"), "{}")
+
+	p, err := LoadFromDisk(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = p.EditConfig("node_package_manager", ConfigSet, "npm"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	for _, format := range ProjectFormats {
+		b, err := Schema(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var schema struct {
+			Properties map[string]struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"properties"`
+		}
+		if err = json.Unmarshal(b, &schema); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		watcher := fieldName(reflect.TypeOf(Project{}), "Watcher", formatTag(format))
+		backend := fieldName(reflect.TypeOf(Watcher{}), "Backend", formatTag(format))
+		if _, ok := schema.Properties[watcher].Properties[backend]; !ok {
+			t.Fatalf("%s: expected the %s.%s property but got:\n%s", format, watcher, backend, b)
+		}
+
+		for _, local := range []string{"Dest", "BuildFiles", "Running"} {
+			if _, ok := schema.Properties[local]; ok {
+				t.Fatalf("%s: expected the local state field %s to not be part of the schema", format, local)
+			}
+		}
+	}
+
+	if _, err := Schema("xml"); err == nil {
+		t.Fatalf("expected an error on unknown format")
+	}
+}
@@ -1,102 +1,610 @@
 package project
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
 	"fmt"
+	"go/version"
 	"io/ioutil"
+	"mime"
 	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 
 	"github.com/kataras/iris-cli/utils"
 
+	"github.com/kataras/golog"
+	"github.com/pelletier/go-toml/v2"
 	"gopkg.in/yaml.v3"
 )
 
 const DefaultRegistryEndpoint = "https://raw.githubusercontent.com/kataras/iris-cli/main/registry.yml"
 
 type Registry struct {
-	Endpoint      string                       `json:"endpoint,omitempty" yaml:"Endpoint" toml:"Endpoint"`
+	Endpoint      string                       `json:"endpoint,omitempty" yaml:"Endpoint,omitempty" toml:"Endpoint"`
 	EndpointAsset func(string) ([]byte, error) `json:"-" yaml:"-" toml:"-"`                      // If EndpointAsset is not nil then it reads the Endpoint from that `EndpointAsset` function.
-	Projects      map[string]string            `json:"projects" yaml:"Projects" toml:"Projects"` // key = name, value = repo.
+	Projects      map[string]*RegistryProject  `json:"projects" yaml:"Projects" toml:"Projects"` // key = name.
 	installed     map[string]struct{}
 	Names         []string `json:"-" yaml:"-" toml:"-"` // sorted Projects names.
+	// Checksums pins the expected SHA-256 of the projects' archives,
+	// key = name@version, value = hex-encoded checksum, optionally prefixed with "sha256:".
+	Checksums map[string]string `json:"checksums,omitempty" yaml:"Checksums,omitempty" toml:"Checksums"`
+	// Cache, if not nil, stores the remote registry file so it can be loaded on Offline mode.
+	Cache   *Cache `json:"-" yaml:"-" toml:"-"`
+	Offline bool   `json:"-" yaml:"-" toml:"-"`
+	// TrustedKeys are the public keys which the registry's detached signature
+	// (Endpoint + `SignatureExt`, appended to the path of a URL) is verified against.
+	TrustedKeys []ed25519.PublicKey `json:"-" yaml:"-" toml:"-"`
+	// Verify set to true to refuse an unsigned registry and
+	// projects without a matching pinned checksum.
+	Verify bool `json:"-" yaml:"-" toml:"-"`
+	// Sources, if not empty, are the registries which are merged on `Load`, instead of the `Endpoint`.
+	Sources []*RegistrySource `json:"-" yaml:"-" toml:"-"`
+	// Collisions are the project names declared by more than one of the `Sources`.
+	Collisions []*RegistryCollision `json:"-" yaml:"-" toml:"-"`
+}
+
+// RegistrySource is a registry to be merged with others, see `Registry.Sources`.
+//
+// Example:
+//
+//	Registries:
+//	  - Endpoint: https://registry.acme.com/registry.yml
+//	    Namespace: acme
+//	    Priority: 10
+//	  - Endpoint: https://raw.githubusercontent.com/kataras/iris-cli/main/registry.yml
+type RegistrySource struct {
+	Endpoint string `json:"endpoint" yaml:"Endpoint" toml:"Endpoint"`
+	// Namespace, if not empty, prefixes the names of the registry's projects, e.g. "acme/service".
+	Namespace string `json:"namespace,omitempty" yaml:"Namespace,omitempty" toml:"Namespace"`
+	// Priority decides which registry's project is used when more than one declare the same name,
+	// the highest wins. Registries with the same priority keep their order.
+	Priority int `json:"priority,omitempty" yaml:"Priority,omitempty" toml:"Priority"`
+	// Override set to true to replace the projects of the lower priority registries
+	// without reporting a collision, e.g. the `DefaultLocalRegistryFile`.
+	// Its signature is not verified.
+	Override bool `json:"override,omitempty" yaml:"Override,omitempty" toml:"Override"`
+}
+
+var namespaceRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// ParseRegistrySource parses a "[namespace=]endpoint" registry source, e.g. "acme=https://registry.acme.com/registry.yml".
+func ParseRegistrySource(s string) *RegistrySource {
+	if i := strings.IndexByte(s, '='); i > 0 && namespaceRegexp.MatchString(s[:i]) {
+		return &RegistrySource{Namespace: s[:i], Endpoint: s[i+1:]}
+	}
+
+	return &RegistrySource{Endpoint: s}
+}
+
+// RegistryCollision describes a project name which is declared by more than one registry.
+type RegistryCollision struct {
+	Name string
+	// Endpoint is the registry which its project is used.
+	Endpoint string
+	// Ignored are the registries which their project is ignored.
+	Ignored []string
+}
+
+func (c *RegistryCollision) String() string {
+	return fmt.Sprintf("project <%s> of <%s> is ignored, <%s> is used instead", c.Name, strings.Join(c.Ignored, ", "), c.Endpoint)
 }
 
 func NewRegistry() *Registry {
 	return &Registry{
 		Endpoint:  DefaultRegistryEndpoint,
-		Projects:  make(map[string]string),
+		Projects:  make(map[string]*RegistryProject),
 		installed: make(map[string]struct{}),
 	}
 }
 
+// Load reads the `Endpoint` registry or merges the `Sources` registries.
 func (r *Registry) Load() error {
-	var (
-		body []byte
-		err  error
-	)
+	if len(r.Sources) > 0 {
+		return r.merge()
+	}
 
-	if r.EndpointAsset != nil {
-		body, err = r.EndpointAsset(r.Endpoint)
-	} else {
-		if isURL := strings.HasPrefix(r.Endpoint, "http"); isURL {
-			if _, urlErr := url.Parse(r.Endpoint); urlErr != nil {
-				return err
+	return r.load()
+}
+
+// merge loads and merges the `Sources`, the projects of a registry with a namespace
+// are prefixed with that namespace. A project name which is already declared
+// by a registry of a higher priority is recorded to the `Collisions`.
+func (r *Registry) merge() error {
+	sources := make([]*RegistrySource, len(r.Sources))
+	copy(sources, r.Sources)
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].Priority > sources[j].Priority })
+
+	if r.Projects == nil {
+		r.Projects = make(map[string]*RegistryProject)
+	}
+	if r.Checksums == nil {
+		r.Checksums = make(map[string]string)
+	}
+	r.Collisions = nil
+	collisions := make(map[string]*RegistryCollision)
+	owners := make(map[string]*RegistrySource)
+
+	for _, src := range sources {
+		sub := &Registry{
+			Endpoint:      src.Endpoint,
+			EndpointAsset: r.EndpointAsset,
+			Projects:      make(map[string]*RegistryProject),
+			Cache:         r.Cache,
+			Offline:       r.Offline,
+		}
+
+		if !src.Override {
+			sub.TrustedKeys, sub.Verify = r.TrustedKeys, r.Verify
+		}
+
+		if err := sub.load(); err != nil {
+			return err
+		}
+
+		for _, name := range sub.Names {
+			project := sub.Projects[name]
+			fullName := name
+			if src.Namespace != "" {
+				fullName = src.Namespace + "/" + name
+			}
+
+			if existing, ok := r.Projects[fullName]; ok {
+				if owner := owners[fullName]; owner != nil && owner.Override {
+					continue
+				}
+
+				c, ok := collisions[fullName]
+				if !ok {
+					c = &RegistryCollision{Name: fullName, Endpoint: existing.Source}
+					collisions[fullName] = c
+					r.Collisions = append(r.Collisions, c)
+				}
+				c.Ignored = append(c.Ignored, src.Endpoint)
+				continue
+			}
+
+			r.Projects[fullName] = project
+			owners[fullName] = src
+			for key, sum := range sub.Checksums {
+				if strings.HasPrefix(key, name+"@") {
+					r.Checksums[fullName+strings.TrimPrefix(key, name)] = sum
+				}
 			}
-			body, err = utils.Download(r.Endpoint, nil)
-		} else {
-			body, err = ioutil.ReadFile(r.Endpoint)
 		}
 	}
 
+	for _, c := range r.Collisions {
+		golog.Warnf("Registry: %s", c)
+	}
+
+	r.sortNames()
+	return nil
+}
+
+func (r *Registry) load() error {
+	endpoint := r.Endpoint
+	body, format, err := r.read(endpoint)
 	if err != nil {
 		return err
 	}
 
-	switch ext := utils.Ext(r.Endpoint); ext {
-	case ".yaml", ".yml":
-		err = yaml.Unmarshal(body, r)
-	default:
-		err = fmt.Errorf("unknown registry file extension: %s", ext)
+	if err = r.verifySignature(body); err != nil {
+		return err
 	}
 
-	if err != nil {
+	if format, err = registryFormat(endpoint, format); err != nil {
 		return err
 	}
 
+	if err = decodeRegistry(body, format, r); err != nil {
+		return fmt.Errorf("registry <%s>: %v", endpoint, err)
+	}
+
+	for name, project := range r.Projects {
+		if project == nil || project.Repo == "" {
+			return fmt.Errorf("registry <%s>: project <%s>: repo is missing", endpoint, name)
+		}
+
+		if project.Source == "" {
+			project.Source = endpoint
+		}
+	}
+
+	r.sortNames()
+	return nil
+}
+
+func (r *Registry) sortNames() {
 	names := make([]string, 0, len(r.Projects))
 	for name := range r.Projects {
 		names = append(names, name)
 	}
 	sort.Strings(names)
 	r.Names = names
+}
+
+// read reads the "endpoint" registry or signature file.
+// It returns the registry format of a remote file's Content-Type, if known.
+func (r *Registry) read(endpoint string) ([]byte, string, error) {
+	if r.EndpointAsset != nil {
+		b, err := r.EndpointAsset(endpoint)
+		return b, "", err
+	}
+
+	if isURL := strings.HasPrefix(endpoint, "http"); isURL {
+		if _, err := url.Parse(endpoint); err != nil {
+			return nil, "", err
+		}
+		return r.download(endpoint)
+	}
+
+	b, err := ioutil.ReadFile(endpoint)
+	return b, "", err
+}
+
+// verifySignature verifies the detached signature of the registry's "body"
+// against the `TrustedKeys`. If the registry is not signed or the signature does not match
+// then it returns an error on `Verify` mode, otherwise it logs a warning.
+func (r *Registry) verifySignature(body []byte) error {
+	if !r.Verify && len(r.TrustedKeys) == 0 {
+		return nil
+	}
+
+	sig, _, err := r.read(signatureEndpoint(r.Endpoint))
+	if err != nil {
+		err = ErrSignatureMissing
+	} else {
+		err = VerifySignature(body, sig, r.TrustedKeys)
+	}
+
+	if err != nil {
+		if r.Verify {
+			return fmt.Errorf("registry <%s>: %w", r.Endpoint, err)
+		}
+
+		golog.Warnf("Registry <%s>: %v", r.Endpoint, err)
+	}
+
 	return nil
 }
 
+func (r *Registry) download(endpoint string) ([]byte, string, error) {
+	if r.Offline {
+		if r.Cache == nil {
+			return nil, "", fmt.Errorf("registry <%s>: %w", endpoint, ErrNotCached)
+		}
+
+		entry, body, err := r.Cache.Get(CacheRegistry, endpoint, "", "")
+		if err != nil {
+			return nil, "", fmt.Errorf("registry <%s>: %w", endpoint, err)
+		}
+
+		return body, entry.Format, nil
+	}
+
+	body, header, err := utils.DownloadWithHeader(endpoint, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	format := contentTypeFormat(header.Get("Content-Type"))
+	if r.Cache != nil {
+		if err = r.Cache.Put(&CacheEntry{Kind: CacheRegistry, Repo: endpoint, Format: format}, body); err != nil {
+			golog.Warnf("Cache: %v", err)
+		}
+	}
+
+	return body, format, nil
+}
+
 // ErrProjectNotExists can be return as error value from the `Registry.Install` method.
 var ErrProjectNotExists = fmt.Errorf("project does not exist")
 
 // Exists reports whether a project with "name" exists in the registry.
 func (r *Registry) Exists(name string) (string, bool) {
-	repo, ok := r.Projects[name]
-	return repo, ok
+	project, ok := r.Projects[name]
+	if !ok {
+		return "", false
+	}
+
+	repo, _ := project.repoVersion()
+	return repo, true
+}
+
+// Filter returns the sorted names of the projects which are tagged with the "tag",
+// all names are returned if "tag" is empty.
+func (r *Registry) Filter(tag string) []string {
+	if tag == "" {
+		return r.Names
+	}
+
+	var names []string
+	for _, name := range r.Names {
+		if r.Projects[name].HasTag(tag) {
+			names = append(names, name)
+		}
+	}
+
+	return names
 }
 
 // Install downloads and unzips a project with "name" to "dest" as "module".
 func (r *Registry) Install(p *Project) error {
-	for projectName, repo := range r.Projects {
-		if projectName != p.Name {
-			continue
-		}
+	if err := r.Resolve(p); err != nil {
+		return err
+	}
+
+	if err := p.Install(); err != nil {
+		return err
+	}
 
+	r.installed[p.Name] = struct{}{}
+	return nil
+}
+
+// Resolve sets the repository of the project, based on its name, and its pinned checksum.
+// The name can be a registry's project name or a direct repository, e.g. "gitlab:owner/repo".
+// An empty version is set to the version of the project's repository, e.g. "owner/repo//path@v1.0.0",
+// its default branch or "main".
+func (r *Registry) Resolve(p *Project) error {
+	p.Verify = p.Verify || r.Verify
+
+	if project, ok := r.Projects[p.Name]; ok {
+		repo, version := project.repoVersion()
 		p.Repo = repo
+		if r.EndpointAsset == nil {
+			p.Repo = localRepo(repo, project.Source)
+		}
+		if p.Version == "" || p.Version == "latest" {
+			if version == "" {
+				version = project.DefaultBranch
+			}
+			if version != "" {
+				p.Version = version
+			}
+		}
+		if p.Version == "" {
+			p.Version = "main"
+		}
 
-		err := p.Install()
-		if err == nil {
-			r.installed[projectName] = struct{}{}
+		if project.GoVersion != "" {
+			if local := utils.GoVersion(); local != "" && !project.SupportsGo(local) {
+				golog.Warnf("Project <%s> requires Go %s or newer, the local toolchain is %s", p.Name, project.GoVersion, local)
+			}
 		}
-		return err
+
+		if sum, ok := r.Checksums[p.Name+"@"+strings.Split(p.Version, " ")[0]]; ok {
+			p.ExpectedSHA256 = sum
+		}
+		return nil
+	}
+
+	// Not a registry project, check if it's a direct repository, e.g. "gitlab:owner/repo".
+	if strings.ContainsAny(p.Name, "/:") {
+		repo, subdir := SplitSubdir(p.Name)
+		if _, _, err := ParseSource(repo); err != nil {
+			return err
+		}
+
+		p.Repo = p.Name
+		if p.Version == "" {
+			p.Version = "main"
+		}
+		if subdir != "" {
+			p.Name = path.Base(subdir)
+		} else {
+			p.Name = strings.TrimSuffix(path.Base(repo), archiveFormat(repo))
+		}
+		return nil
 	}
 
 	return ErrProjectNotExists
 }
+
+// localRepo resolves a relative file:// "repo" of a local registry file, its "source",
+// against the registry file's directory, so a registry and its templates can be moved together.
+func localRepo(repo, source string) string {
+	if !strings.HasPrefix(repo, "file://") || source == "" || strings.Contains(source, "://") {
+		return repo
+	}
+
+	repo, subdir := SplitSubdir(repo)
+	name := strings.TrimPrefix(repo, "file://")
+	if name == "" || filepath.IsAbs(filepath.FromSlash(name)) || path.IsAbs(name) {
+		return JoinSubdir(repo, subdir)
+	}
+
+	repo = "file://" + filepath.ToSlash(filepath.Join(filepath.Dir(source), filepath.FromSlash(name)))
+	return JoinSubdir(repo, subdir)
+}
+
+// Registry file formats.
+const (
+	RegistryYAML = "yaml"
+	RegistryJSON = "json"
+	RegistryTOML = "toml"
+)
+
+// registryFormat returns the format of the "endpoint" registry file based on its extension,
+// the "contentTypeFormat" is used when the endpoint has no known extension.
+func registryFormat(endpoint, contentTypeFormat string) (string, error) {
+	name := endpoint
+	if u, err := url.Parse(endpoint); err == nil && u.Scheme != "" && u.Path != "" {
+		name = u.Path // without the query.
+	}
+
+	switch ext := strings.ToLower(path.Ext(filepath.ToSlash(name))); ext {
+	case ".yaml", ".yml":
+		return RegistryYAML, nil
+	case ".json":
+		return RegistryJSON, nil
+	case ".toml", ".tml":
+		return RegistryTOML, nil
+	default:
+		if contentTypeFormat != "" {
+			return contentTypeFormat, nil
+		}
+
+		if ext == "" {
+			return "", fmt.Errorf("registry <%s>: unknown registry format, expected a .yml, .json or .toml file or a Content-Type of them", endpoint)
+		}
+
+		return "", fmt.Errorf("unknown registry file extension: %s", ext)
+	}
+}
+
+// contentTypeFormat returns the registry format of a Content-Type header value, if known.
+func contentTypeFormat(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return RegistryJSON
+	case mediaType == "application/toml" || mediaType == "text/toml" || mediaType == "text/x-toml":
+		return RegistryTOML
+	case strings.HasSuffix(mediaType, "/yaml") || strings.HasSuffix(mediaType, "/x-yaml") || strings.HasSuffix(mediaType, "+yaml"):
+		return RegistryYAML
+	default:
+		return ""
+	}
+}
+
+// decodeRegistry decodes the registry file "body" of "format" to "r".
+// TOML is decoded through YAML, as their field names are the same,
+// so the projects can be declared as their repository only too.
+func decodeRegistry(body []byte, format string, r *Registry) error {
+	switch format {
+	case RegistryYAML:
+		return yaml.Unmarshal(body, r)
+	case RegistryJSON:
+		return json.Unmarshal(body, r)
+	case RegistryTOML:
+		var v map[string]interface{}
+		if err := toml.Unmarshal(body, &v); err != nil {
+			return err
+		}
+
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		return yaml.Unmarshal(b, r)
+	default:
+		return fmt.Errorf("unknown registry format: %s", format)
+	}
+}
+
+// RegistryProject is a project entry of a registry.
+// It can be declared as the repository only, e.g. "basic: iris-contrib/basic-template",
+// or as a map of the repository and its metadata.
+//
+// Example:
+//
+//	Projects:
+//	  basic: iris-contrib/basic-template
+//	  go-admin:
+//	    Repo: iris-contrib/go-admin-template
+//	    Description: Admin dashboard with authentication
+//	    Tags: [admin, mvc]
+//	    GoVersion: "1.21"
+//	    IrisVersion: 12
+//	    DefaultBranch: master
+//	    Maintainers: [kataras]
+type RegistryProject struct {
+	Repo        string   `json:"repo" yaml:"Repo" toml:"Repo"`
+	Description string   `json:"description,omitempty" yaml:"Description,omitempty" toml:"Description"`
+	Tags        []string `json:"tags,omitempty" yaml:"Tags,omitempty" toml:"Tags"`
+	// GoVersion is the minimum Go version, e.g. "1.21".
+	GoVersion string `json:"go_version,omitempty" yaml:"GoVersion,omitempty" toml:"GoVersion"`
+	// IrisVersion is the Iris major version, e.g. 12.
+	IrisVersion int `json:"iris_version,omitempty" yaml:"IrisVersion,omitempty" toml:"IrisVersion"`
+	// DefaultBranch is the version which is installed when no version is specified.
+	DefaultBranch string   `json:"default_branch,omitempty" yaml:"DefaultBranch,omitempty" toml:"DefaultBranch"`
+	Maintainers   []string `json:"maintainers,omitempty" yaml:"Maintainers,omitempty" toml:"Maintainers"`
+	// Source is the endpoint of the registry which declares the project, it is set on `Registry.Load`.
+	Source string `json:"-" yaml:"-" toml:"-"`
+}
+
+// registryProject is the alias of the RegistryProject without its encoding methods.
+type registryProject RegistryProject
+
+// UnmarshalYAML decodes the repository only or the full form of a registry project.
+func (p *RegistryProject) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&p.Repo)
+	}
+
+	return value.Decode((*registryProject)(p))
+}
+
+// MarshalYAML encodes a registry project without metadata as its repository only.
+func (p *RegistryProject) MarshalYAML() (interface{}, error) {
+	if p.isRepoOnly() {
+		return p.Repo, nil
+	}
+
+	return (*registryProject)(p), nil
+}
+
+// UnmarshalJSON decodes the repository only or the full form of a registry project.
+func (p *RegistryProject) UnmarshalJSON(b []byte) error {
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '"' {
+		return json.Unmarshal(b, &p.Repo)
+	}
+
+	return json.Unmarshal(b, (*registryProject)(p))
+}
+
+// MarshalJSON encodes a registry project without metadata as its repository only.
+func (p *RegistryProject) MarshalJSON() ([]byte, error) {
+	if p.isRepoOnly() {
+		return json.Marshal(p.Repo)
+	}
+
+	return json.Marshal((*registryProject)(p))
+}
+
+// repoVersion returns the project's repository and the version pinned in it, if any,
+// e.g. "kataras/iris//_examples/mvc" and "v12.2.0" of "kataras/iris//_examples/mvc@v12.2.0".
+func (p *RegistryProject) repoVersion() (string, string) {
+	return utils.SplitNameVersion(p.Repo)
+}
+
+func (p *RegistryProject) isRepoOnly() bool {
+	return p.Description == "" && len(p.Tags) == 0 && p.GoVersion == "" && p.IrisVersion == 0 &&
+		p.DefaultBranch == "" && len(p.Maintainers) == 0
+}
+
+// HasTag reports whether the project is tagged with "tag", case-insensitive.
+func (p *RegistryProject) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SupportsGo reports whether the "goVersion", e.g. "go1.22.1", satisfies the project's minimum `GoVersion`.
+func (p *RegistryProject) SupportsGo(goVersion string) bool {
+	if p.GoVersion == "" {
+		return true
+	}
+
+	required := "go" + strings.TrimPrefix(p.GoVersion, "go")
+	if !version.IsValid(required) || !version.IsValid(goVersion) {
+		return true
+	}
+
+	return version.Compare(goVersion, required) >= 0
+}
@@ -1,18 +1,26 @@
 package project
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/kataras/iris-cli/utils"
+
 	"gopkg.in/yaml.v3"
 )
 
 func TestRegistry(t *testing.T) {
 	var (
-		expected = &Registry{Projects: map[string]string{
-			"iris":      "github.com/kataras/iris",
-			"neffos":    "github.com/kataras/neffos",
-			"neffos.js": "github.com/kataras/neffos.js",
+		expected = &Registry{Projects: map[string]*RegistryProject{
+			"iris":      {Repo: "github.com/kataras/iris", Source: "./test.yml"},
+			"neffos":    {Repo: "github.com/kataras/neffos", Source: "./test.yml"},
+			"neffos.js": {Repo: "github.com/kataras/neffos.js", Description: "neffos client", Tags: []string{"websocket", "js"}, Source: "./test.yml"},
 		}}
 
 		tests = []func(*Registry) *Registry{
@@ -38,6 +46,352 @@ func TestRegistry(t *testing.T) {
 	}
 }
 
+func TestRegistryProjects(t *testing.T) {
+	reg := NewRegistry()
+	reg.Endpoint = "./test.yml"
+	reg.EndpointAsset = func(string) ([]byte, error) {
+		return []byte(`Projects:
+  basic: iris-contrib/basic-template
+  go-admin:
+    Repo: iris-contrib/go-admin-template
+    Description: Admin dashboard
+    Tags: [admin, MVC]
+    GoVersion: "1.21"
+    IrisVersion: 12
+    DefaultBranch: master
+    Maintainers: [kataras]
+`), nil
+	}
+
+	if err := reg.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &RegistryProject{
+		Repo:          "iris-contrib/go-admin-template",
+		Description:   "Admin dashboard",
+		Tags:          []string{"admin", "MVC"},
+		GoVersion:     "1.21",
+		IrisVersion:   12,
+		DefaultBranch: "master",
+		Maintainers:   []string{"kataras"},
+		Source:        "./test.yml",
+	}
+	if got := reg.Projects["go-admin"]; !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected:\n%#+v\nbut got:\n%#+v", expected, got)
+	}
+
+	if expected, got := "iris-contrib/basic-template", reg.Projects["basic"].Repo; expected != got {
+		t.Fatalf("expected repo: %s but got: %s", expected, got)
+	}
+
+	if expected, got := []string{"go-admin"}, reg.Filter("mvc"); !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected filtered names: %v but got: %v", expected, got)
+	}
+
+	for goVersion, expected := range map[string]bool{"go1.20.5": false, "go1.21": true, "go1.22.1": true} {
+		if got := reg.Projects["go-admin"].SupportsGo(goVersion); expected != got {
+			t.Fatalf("%s: expected supported: %v but got: %v", goVersion, expected, got)
+		}
+	}
+
+	// The versions of the command line arguments, e.g. iris-cli new go-admin.
+	for arg, expected := range map[string]string{
+		"go-admin":                  "master", // the default branch.
+		"go-admin@v1.0.0":           "v1.0.0",
+		"go-admin@latest":           "master",
+		"basic":                     "main",
+		"iris-contrib/mvc-template": "main",
+	} {
+		p := new(Project)
+		p.Name, p.Version = utils.SplitNameVersion(arg)
+		if err := reg.Resolve(p); err != nil {
+			t.Fatalf("%s: %v", arg, err)
+		}
+
+		if got := p.Version; expected != got {
+			t.Fatalf("%s: expected version: %s but got: %s", arg, expected, got)
+		}
+	}
+
+	// The projects without metadata are encoded in the map form.
+	b, err := yaml.Marshal(&Registry{Projects: map[string]*RegistryProject{"basic": reg.Projects["basic"]}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "basic: iris-contrib/basic-template", string(b); !strings.Contains(got, expected) {
+		t.Fatalf("expected encoded registry to contain: %q but got:\n%s", expected, got)
+	}
+}
+
+func TestRegistrySources(t *testing.T) {
+	files := map[string]string{
+		"public.yml": `Projects:
+  basic: iris-contrib/basic-template
+  mvc: iris-contrib/mvc-template
+Checksums:
+  basic@v1.0.0: sha256:public
+`,
+		"acme.yml": `Projects:
+  basic: acme/basic-template
+  service: acme/service-template
+Checksums:
+  service@v1.0.0: sha256:acme
+`,
+		"mirror.yml": `Projects:
+  mvc: mirror/mvc-template
+`,
+	}
+
+	reg := NewRegistry()
+	reg.EndpointAsset = func(endpoint string) ([]byte, error) {
+		return []byte(files[endpoint]), nil
+	}
+	reg.Sources = []*RegistrySource{
+		{Endpoint: "mirror.yml"},
+		ParseRegistrySource("acme=acme.yml"),
+		{Endpoint: "public.yml", Priority: 1},
+	}
+
+	if err := reg.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := []string{"acme/basic", "acme/service", "basic", "mvc"}, reg.Names; !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected names: %v but got: %v", expected, got)
+	}
+
+	for name, expected := range map[string]string{
+		"basic":        "iris-contrib/basic-template",
+		"mvc":          "iris-contrib/mvc-template", // higher priority.
+		"acme/basic":   "acme/basic-template",
+		"acme/service": "acme/service-template",
+	} {
+		if got := reg.Projects[name].Repo; expected != got {
+			t.Fatalf("%s: expected repo: %s but got: %s", name, expected, got)
+		}
+	}
+
+	if expected, got := "acme.yml", reg.Projects["acme/service"].Source; expected != got {
+		t.Fatalf("expected source: %s but got: %s", expected, got)
+	}
+
+	if expected, got := 1, len(reg.Collisions); expected != got {
+		t.Fatalf("expected %d collisions but got %d", expected, got)
+	}
+
+	if c := reg.Collisions[0]; c.Name != "mvc" || c.Endpoint != "public.yml" || !reflect.DeepEqual(c.Ignored, []string{"mirror.yml"}) {
+		t.Fatalf("unexpected collision: %s", c)
+	}
+
+	if expected, got := map[string]string{"basic@v1.0.0": "sha256:public", "acme/service@v1.0.0": "sha256:acme"}, reg.Checksums; !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected checksums: %v but got: %v", expected, got)
+	}
+}
+
+func TestRegistryFormats(t *testing.T) {
+	files := map[string]string{
+		"./registry.yml": `Projects:
+  basic: iris-contrib/basic-template
+  go-admin:
+    Repo: iris-contrib/go-admin-template
+    Tags: [admin]
+Checksums:
+  basic@v1.0.0: sha256:basic
+`,
+		"./registry.json": `{
+  "projects": {
+    "basic": "iris-contrib/basic-template",
+    "go-admin": {"repo": "iris-contrib/go-admin-template", "tags": ["admin"]}
+  },
+  "checksums": {"basic@v1.0.0": "sha256:basic"}
+}`,
+		"./registry.toml": `[Projects]
+basic = "iris-contrib/basic-template"
+
+[Projects.go-admin]
+Repo = "iris-contrib/go-admin-template"
+Tags = ["admin"]
+
+[Checksums]
+"basic@v1.0.0" = "sha256:basic"
+`,
+	}
+
+	for endpoint := range files {
+		reg := NewRegistry()
+		reg.Endpoint = endpoint
+		reg.EndpointAsset = func(endpoint string) ([]byte, error) {
+			return []byte(files[endpoint]), nil
+		}
+
+		if err := reg.Load(); err != nil {
+			t.Fatalf("%s: %v", endpoint, err)
+		}
+
+		if expected, got := []string{"basic", "go-admin"}, reg.Names; !reflect.DeepEqual(expected, got) {
+			t.Fatalf("%s: expected names: %v but got: %v", endpoint, expected, got)
+		}
+
+		if expected, got := "iris-contrib/basic-template", reg.Projects["basic"].Repo; expected != got {
+			t.Fatalf("%s: expected repo: %s but got: %s", endpoint, expected, got)
+		}
+
+		if !reg.Projects["go-admin"].HasTag("admin") {
+			t.Fatalf("%s: expected go-admin to be tagged", endpoint)
+		}
+
+		if expected, got := "sha256:basic", reg.Checksums["basic@v1.0.0"]; expected != got {
+			t.Fatalf("%s: expected checksum: %s but got: %s", endpoint, expected, got)
+		}
+	}
+
+	reg := NewRegistry()
+	reg.Endpoint = "./registry.xml"
+	reg.EndpointAsset = func(string) ([]byte, error) { return nil, nil }
+	if err := reg.Load(); err == nil {
+		t.Fatalf("expected an error on unknown registry file extension")
+	}
+}
+
+func TestRegistryContentType(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"projects": {"basic": "iris-contrib/basic-template"}}`))
+		case "/toml":
+			w.Header().Set("Content-Type", "application/toml")
+			w.Write([]byte(`Projects = { basic = "iris-contrib/basic-template" }`))
+		case "/yaml":
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write([]byte("Projects:\n  basic: iris-contrib/basic-template\n"))
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("Projects:\n  basic: iris-contrib/basic-template\n"))
+		}
+	}))
+	defer srv.Close()
+
+	for _, name := range []string{"json", "toml", "yaml"} {
+		reg := NewRegistry()
+		reg.Endpoint = srv.URL + "/" + name
+		if err := reg.Load(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if expected, got := "iris-contrib/basic-template", reg.Projects["basic"].Repo; expected != got {
+			t.Fatalf("%s: expected repo: %s but got: %s", name, expected, got)
+		}
+	}
+
+	reg := NewRegistry()
+	reg.Endpoint = srv.URL + "/plain"
+	if err := reg.Load(); err == nil {
+		t.Fatalf("expected an error on unknown registry content type")
+	}
+}
+
+func TestRegistryValidate(t *testing.T) {
+	files := map[string]string{
+		"./valid.yml": `Projects:
+  basic: iris-contrib/basic-template
+  go-admin:
+    Repo: iris-contrib/go-admin-template
+    GoVersion: "1.21"
+Checksums:
+  basic@v1.0.0: sha256:4f680143493ee70bd4641495be16e77679c6d2594f680143493ee70bd4641495
+`,
+		"./invalid.yml": `Projects:
+  basic:
+    Repo: iris-contrib/basic-template
+    GoVersion: latest
+    Unknown: true
+  empty:
+    Description: no repo
+Checksums:
+  missing@v1.0.0: sha256:abc
+`,
+		"./duplicate.json": `{"projects": {"basic": "iris-contrib/basic-template", "basic": "iris-contrib/mvc-template"}}`,
+		"./duplicate.yml":  "Projects:\n  basic: iris-contrib/basic-template\n  basic: iris-contrib/mvc-template\n",
+	}
+
+	tests := []struct {
+		endpoint string
+		expected []string
+	}{
+		{"./valid.yml", nil},
+		{"./invalid.yml", []string{
+			"project <basic>: unknown field: Unknown",
+			"project <basic>: invalid Go version: latest",
+			"project <empty>: repo is missing",
+			"checksum <missing@v1.0.0>: project does not exist",
+			"checksum <missing@v1.0.0>: expected a hex-encoded SHA-256",
+		}},
+		{"./duplicate.json", []string{"project <basic>: duplicate name"}},
+		{"./duplicate.yml", []string{`yaml: unmarshal errors:
+  line 3: mapping key "basic" already defined at line 2`}},
+	}
+
+	for _, tt := range tests {
+		reg := NewRegistry()
+		reg.Endpoint = tt.endpoint
+		reg.EndpointAsset = func(endpoint string) ([]byte, error) {
+			return []byte(files[endpoint]), nil
+		}
+
+		issues, err := reg.Validate(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := make([]string, 0, len(issues))
+		for _, issue := range issues {
+			got = append(got, issue.Error())
+		}
+
+		if len(tt.expected) != len(got) || len(got) > 0 && !reflect.DeepEqual(tt.expected, got) {
+			t.Fatalf("%s: expected issues:\n%s\nbut got:\n%s", tt.endpoint, strings.Join(tt.expected, "\n"), strings.Join(got, "\n"))
+		}
+	}
+}
+
+func TestRegistrySaveFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"registry.yml", "registry.json", "registry.toml"} {
+		file := filepath.Join(dir, name)
+
+		reg, err := LoadRegistryFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		reg.Projects["basic"] = &RegistryProject{Repo: "iris-contrib/basic-template"}
+		reg.Projects["go-admin"] = &RegistryProject{Repo: "iris-contrib/go-admin-template", Tags: []string{"admin"}}
+		if err = reg.SaveFile(file); err != nil {
+			t.Fatal(err)
+		}
+
+		if reg, err = LoadRegistryFile(file); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if expected, got := []string{"basic", "go-admin"}, reg.Names; !reflect.DeepEqual(expected, got) {
+			t.Fatalf("%s: expected names: %v but got: %v", name, expected, got)
+		}
+
+		if !reg.Projects["go-admin"].HasTag("admin") {
+			t.Fatalf("%s: expected go-admin to be tagged", name)
+		}
+	}
+}
+
 func newTestRegistryEndpointAsset(expectedProjects *Registry) *Registry {
 	reg := NewRegistry()
 	reg.Endpoint = "./test.yml"
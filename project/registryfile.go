@@ -0,0 +1,102 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kataras/iris-cli/utils"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocalRegistryFile returns the "registry.yml" file next to the `DefaultUserConfigFile`.
+// Its projects are added to the loaded registries and they override the ones with the same name,
+// see `registry add` and `registry remove` commands.
+func DefaultLocalRegistryFile() string {
+	return filepath.Join(filepath.Dir(DefaultUserConfigFile()), "registry.yml")
+}
+
+// LoadRegistryFile reads a local registry "file".
+// A missing file results to an empty registry.
+func LoadRegistryFile(file string) (*Registry, error) {
+	r := NewRegistry()
+	r.Endpoint = file
+	if !utils.Exists(file) {
+		return r, nil
+	}
+
+	if err := r.Load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// SaveFile writes the registry's projects and checksums to the "file",
+// the format is based on the file extension.
+func (r *Registry) SaveFile(file string) error {
+	format, err := registryFormat(file, "")
+	if err != nil {
+		return err
+	}
+
+	b, err := r.Encode(format)
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(file); dir != "" {
+		if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	return os.WriteFile(file, b, 0644)
+}
+
+// Encode returns the registry's projects and checksums encoded in "format",
+// one of `RegistryYAML`, `RegistryJSON` and `RegistryTOML`.
+func (r *Registry) Encode(format string) ([]byte, error) {
+	v := &Registry{Projects: r.Projects, Checksums: r.Checksums}
+
+	switch format {
+	case RegistryYAML:
+		buf := new(bytes.Buffer)
+		enc := yaml.NewEncoder(buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
+
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	case RegistryJSON:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	case RegistryTOML:
+		// Encoded through YAML, so the projects without metadata are written as their repository only.
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		var m map[string]interface{}
+		if err = yaml.Unmarshal(b, &m); err != nil {
+			return nil, err
+		}
+
+		return toml.Marshal(m)
+	default:
+		return nil, fmt.Errorf("unknown registry format: %s", format)
+	}
+}
@@ -0,0 +1,138 @@
+package project
+
+import (
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// RegistryServer serves the local template directories of `Dir` as a registry,
+// so they can be installed without a GitHub access, e.g. `iris-cli new --registry=http://localhost:8080/registry.yml`.
+//
+// Each directory of the `Dir` is a project named after the directory,
+// its zip archive is generated on each request at: /archives/{name}.zip.
+// The registry file is generated at: /registry.yml, /registry.json and /registry.toml.
+// If the `Dir` contains a registry.yml file then its projects' descriptions, tags and
+// the rest of their metadata are used, their repositories are replaced by the archive URLs.
+type RegistryServer struct {
+	Dir string
+}
+
+// NewRegistryServer returns a new registry server of the "dir" template directories.
+func NewRegistryServer(dir string) *RegistryServer {
+	return &RegistryServer{Dir: dir}
+}
+
+// Registry generates the registry of the `Dir` templates,
+// the archive URLs are relative to the "baseURL", e.g. http://localhost:8080.
+func (s *RegistryServer) Registry(baseURL string) (*Registry, error) {
+	meta, err := LoadRegistryFile(filepath.Join(s.Dir, "registry.yml"))
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	reg := NewRegistry()
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		p := new(RegistryProject)
+		if m, ok := meta.Projects[name]; ok && m != nil {
+			*p = *m
+		}
+		p.Repo = baseURL + "/archives/" + name + ".zip"
+		p.Source = ""
+
+		reg.Projects[name] = p
+	}
+	reg.sortNames()
+
+	return reg, nil
+}
+
+// ServeHTTP serves the registry files and the project archives.
+func (s *RegistryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	reqPath := path.Clean("/" + r.URL.Path)
+
+	if name := strings.TrimPrefix(reqPath, "/archives/"); name != reqPath {
+		s.serveArchive(w, r, name)
+		return
+	}
+
+	format, err := registryFormat(reqPath, "")
+	if err != nil || path.Base(reqPath) != "registry"+path.Ext(reqPath) {
+		http.NotFound(w, r)
+		return
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	reg, err := s.Registry(scheme + "://" + r.Host)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	b, err := reg.Encode(format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch format {
+	case RegistryJSON:
+		w.Header().Set("Content-Type", "application/json")
+	case RegistryTOML:
+		w.Header().Set("Content-Type", "application/toml")
+	default:
+		w.Header().Set("Content-Type", "application/yaml")
+	}
+	w.Write(b)
+}
+
+func (s *RegistryServer) serveArchive(w http.ResponseWriter, r *http.Request, name string) {
+	if !strings.HasSuffix(name, ".zip") {
+		http.NotFound(w, r)
+		return
+	}
+
+	name = strings.TrimSuffix(name, ".zip")
+	if name == "" || strings.ContainsAny(name, "/\\") || strings.HasPrefix(name, ".") {
+		http.NotFound(w, r)
+		return
+	}
+
+	dir := filepath.Join(s.Dir, name)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	b, err := zipDir(dir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Write(b)
+}
@@ -0,0 +1,85 @@
+package project
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistryServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	templates := filepath.Join(dir, "templates")
+	writeTestFile(t, filepath.Join(templates, "basic", "go.mod"), "module github.com/iris-contrib/basic\n")
+	writeTestFile(t, filepath.Join(templates, "basic", "main.go"), "package main\n\nimport _ \"github.com/iris-contrib/basic/routes\"\n")
+	writeTestFile(t, filepath.Join(templates, "basic", "routes", "routes.go"), "package routes\n")
+	writeTestFile(t, filepath.Join(templates, "mvc", "go.mod"), "module github.com/iris-contrib/mvc\n")
+	writeTestFile(t, filepath.Join(templates, ".hidden", "go.mod"), "module hidden\n")
+	writeTestFile(t, filepath.Join(templates, "registry.yml"), "Projects:\n  mvc:\n    Repo: ignored\n    Description: MVC app\n    Tags: [mvc]\n")
+
+	srv := httptest.NewServer(NewRegistryServer(templates))
+	defer srv.Close()
+
+	for _, endpoint := range []string{"/registry.yml", "/registry.json", "/registry.toml"} {
+		reg := NewRegistry()
+		reg.Endpoint = srv.URL + endpoint
+		if err = reg.Load(); err != nil {
+			t.Fatalf("%s: %v", endpoint, err)
+		}
+
+		if expected, got := 2, len(reg.Names); expected != got {
+			t.Fatalf("%s: expected %d projects but got %d: %v", endpoint, expected, got, reg.Names)
+		}
+
+		if expected, got := srv.URL+"/archives/basic.zip", reg.Projects["basic"].Repo; expected != got {
+			t.Fatalf("%s: expected repo: %s but got: %s", endpoint, expected, got)
+		}
+
+		if expected, got := "MVC app", reg.Projects["mvc"].Description; expected != got {
+			t.Fatalf("%s: expected description: %s but got: %s", endpoint, expected, got)
+		}
+	}
+
+	reg := NewRegistry()
+	reg.Endpoint = srv.URL + "/registry.yml"
+	if err = reg.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, ok := reg.Exists("basic")
+	if !ok {
+		t.Fatalf("expected project <basic> to exist")
+	}
+
+	p := &Project{
+		Repo:   repo,
+		Dest:   filepath.Join(dir, "app"),
+		Module: "github.com/author/app",
+	}
+	if err = p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "package main\n\nimport _ \"github.com/author/app/routes\"\n", readTestFile(t, filepath.Join(p.Dest, "main.go")); expected != got {
+		t.Fatalf("expected main.go contents:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	for _, path := range []string{"/archives/.hidden.zip", "/archives/missing.zip", "/archives/basic", "/archives/../basic.zip", "/other.yml"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if expected, got := http.StatusNotFound, resp.StatusCode; expected != got {
+			t.Fatalf("%s: expected status code: %d but got: %d", path, expected, got)
+		}
+	}
+}
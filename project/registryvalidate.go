@@ -0,0 +1,333 @@
+package project
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/version"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// RegistryIssue is a problem of a registry file, see `Registry.Validate`.
+type RegistryIssue struct {
+	// Name is the project's name, empty for the issues of the whole file.
+	Name string
+	Err  error
+}
+
+func (i *RegistryIssue) Error() string {
+	if i.Name == "" {
+		return i.Err.Error()
+	}
+
+	return fmt.Sprintf("project <%s>: %v", i.Name, i.Err)
+}
+
+// Validate reads the `Endpoint` registry file and reports its issues:
+// unknown fields, duplicate project names, invalid repositories, Go versions and checksums.
+// If "checkArchives" is true then the archive of each project's default branch
+// (defaults to "main") is downloaded, or read from the `Cache`, to check that it contains a go.mod file.
+// It returns an error only if the file cannot be read.
+func (r *Registry) Validate(checkArchives bool) ([]*RegistryIssue, error) {
+	body, format, err := r.read(r.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if format, err = registryFormat(r.Endpoint, format); err != nil {
+		return []*RegistryIssue{{Err: err}}, nil
+	}
+
+	var issues []*RegistryIssue
+	addIssue := func(name string, format string, args ...interface{}) {
+		issues = append(issues, &RegistryIssue{Name: name, Err: fmt.Errorf(format, args...)})
+	}
+
+	// Duplicate names, YAML and TOML decoders report them as errors.
+	if format == RegistryJSON {
+		names, err := jsonObjectKeys(body, "projects")
+		if err != nil {
+			return []*RegistryIssue{{Err: err}}, nil
+		}
+
+		seen := make(map[string]struct{}, len(names))
+		for _, name := range names {
+			if _, ok := seen[name]; ok {
+				addIssue(name, "duplicate name")
+			}
+			seen[name] = struct{}{}
+		}
+
+		if len(issues) > 0 {
+			return issues, nil
+		}
+	}
+
+	// Schema.
+	var m map[string]interface{}
+	switch format {
+	case RegistryJSON:
+		err = json.Unmarshal(body, &m)
+	case RegistryTOML:
+		err = toml.Unmarshal(body, &m)
+	default:
+		err = yaml.Unmarshal(body, &m)
+	}
+	if err != nil {
+		return []*RegistryIssue{{Err: err}}, nil
+	}
+
+	tag := "yaml" // TOML is decoded through YAML.
+	if format == RegistryJSON {
+		tag = "json"
+	}
+
+	for _, key := range unknownFields(m, reflect.TypeOf(Registry{}), tag) {
+		addIssue("", "unknown field: %s", key)
+	}
+
+	projectsField := fieldName(reflect.TypeOf(Registry{}), "Projects", tag)
+	for key, projects := range m {
+		if key != projectsField && !(tag == "json" && strings.EqualFold(key, projectsField)) {
+			continue
+		}
+
+		projectsMap, ok := projects.(map[string]interface{})
+		if !ok {
+			addIssue("", "%s: expected a map of project names", key)
+			continue
+		}
+
+		for name, v := range projectsMap {
+			fields, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			for _, key := range unknownFields(fields, reflect.TypeOf(RegistryProject{}), tag) {
+				addIssue(name, "unknown field: %s", key)
+			}
+		}
+	}
+
+	reg := &Registry{Projects: make(map[string]*RegistryProject)}
+	if err = decodeRegistry(body, format, reg); err != nil {
+		return append(issues, &RegistryIssue{Err: err}), nil
+	}
+	reg.sortNames()
+
+	if len(reg.Names) == 0 {
+		addIssue("", "no projects")
+	}
+
+	for _, name := range reg.Names {
+		p := reg.Projects[name]
+		if p == nil || p.Repo == "" {
+			addIssue(name, "repo is missing")
+			continue
+		}
+
+		repo, _ := p.repoVersion()
+		repo, _ = SplitSubdir(repo)
+		if _, _, err = ParseSource(repo); err != nil {
+			addIssue(name, "repo: %v", err)
+		}
+
+		if p.GoVersion != "" && !version.IsValid("go"+strings.TrimPrefix(p.GoVersion, "go")) {
+			addIssue(name, "invalid Go version: %s", p.GoVersion)
+		}
+
+		if p.IrisVersion < 0 {
+			addIssue(name, "invalid Iris version: %d", p.IrisVersion)
+		}
+
+		for _, t := range p.Tags {
+			if strings.TrimSpace(t) == "" {
+				addIssue(name, "empty tag")
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(reg.Checksums))
+	for key := range reg.Checksums {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name, ver := key, ""
+		if i := strings.LastIndexByte(key, '@'); i > 0 {
+			name, ver = key[:i], key[i+1:]
+		}
+
+		if ver == "" {
+			addIssue("", "checksum <%s>: expected a name@version key", key)
+		} else if _, ok := reg.Projects[name]; !ok {
+			addIssue("", "checksum <%s>: project does not exist", key)
+		}
+
+		sum := strings.TrimPrefix(strings.TrimSpace(reg.Checksums[key]), "sha256:")
+		if b, err := hex.DecodeString(sum); err != nil || len(b) != 32 {
+			addIssue("", "checksum <%s>: expected a hex-encoded SHA-256", key)
+		}
+	}
+
+	if checkArchives {
+		for _, name := range reg.Names {
+			if err = r.checkArchive(name, reg.Projects[name]); err != nil {
+				addIssue(name, "%v", err)
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+// checkArchive downloads the archive of the project's default branch
+// and reports whether it contains a go.mod file.
+func (r *Registry) checkArchive(name string, project *RegistryProject) error {
+	if project.Repo == "" {
+		return nil
+	}
+
+	repo, version := project.repoVersion()
+	if version == "" {
+		version = project.DefaultBranch
+	}
+
+	p := &Project{Name: name, Repo: repo, Version: version, Cache: r.Cache, Offline: r.Offline}
+	if p.Version == "" {
+		p.Version = "main"
+	}
+
+	b, format, err := p.download()
+	if err != nil {
+		return fmt.Errorf("archive of <%s>: %v", p.Version, err)
+	}
+
+	files, err := readArchive(b, format, DefaultMaxSize)
+	if err != nil {
+		return fmt.Errorf("archive of <%s>: %v", p.Version, err)
+	}
+
+	_, subdir := SplitSubdir(p.Repo)
+	mod, err := findModule(files, archiveRoot(files), subdir)
+	if err != nil {
+		return fmt.Errorf("archive of <%s>: %v", p.Version, err)
+	}
+
+	if mod == nil {
+		return fmt.Errorf("archive of <%s> does not contain a go.mod file", p.Version)
+	}
+
+	return nil
+}
+
+// jsonObjectKeys returns the keys of the "field" object of a JSON document, including the duplicates.
+// The "field" is matched case-insensitively, as the encoding/json package does.
+func jsonObjectKeys(body []byte, field string) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+
+	if t, err := dec.Token(); err != nil {
+		return nil, err
+	} else if t != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		if key, _ := t.(string); !strings.EqualFold(key, field) {
+			var skip json.RawMessage
+			if err = dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if t, err = dec.Token(); err != nil {
+			return nil, err
+		} else if t != json.Delim('{') {
+			return nil, fmt.Errorf("%s: expected a JSON object", field)
+		}
+
+		var keys []string
+		for dec.More() {
+			t, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			keys = append(keys, t.(string))
+
+			var skip json.RawMessage
+			if err = dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+		}
+
+		return keys, nil
+	}
+
+	return nil, nil
+}
+
+// fieldName returns the encoded name of the "typ" struct's "field" based on its "tag", e.g. "yaml".
+func fieldName(typ reflect.Type, field, tag string) string {
+	f, ok := typ.FieldByName(field)
+	if !ok {
+		return field
+	}
+
+	if name := strings.Split(f.Tag.Get(tag), ",")[0]; name != "" {
+		return name
+	}
+
+	return field
+}
+
+// unknownFields returns the sorted keys of "m" which are not encoded fields of the "typ" struct.
+// JSON keys are matched case-insensitively, as the encoding/json package does.
+func unknownFields(m map[string]interface{}, typ reflect.Type, tag string) []string {
+	known := make(map[string]struct{})
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		name := strings.Split(f.Tag.Get(tag), ",")[0]
+		if f.PkgPath != "" || name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		if tag == "json" {
+			name = strings.ToLower(name)
+		}
+		known[name] = struct{}{}
+	}
+
+	var unknown []string
+	for key := range m {
+		name := key
+		if tag == "json" {
+			name = strings.ToLower(name)
+		}
+
+		if _, ok := known[name]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+
+	return unknown
+}
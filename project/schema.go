@@ -0,0 +1,127 @@
+package project
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// SchemaURI is the JSON Schema dialect of the `Schema`.
+const SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaKeywords are the descriptions and the constraints of the project file's fields,
+// by "Type.Field". The constraints are the ones checked on `LoadFromDisk`.
+var schemaKeywords = map[string]map[string]interface{}{
+	"Project.Name":                  {"description": "The name of the project's template, e.g. starter-kit."},
+	"Project.Repo":                  {"description": "The repository of the project's template, e.g. iris-contrib/starter-kit."},
+	"Project.Version":               {"description": "The installed version of the repository, defaults to main."},
+	"Project.Commit":                {"description": "The resolved commit hash of the installed version."},
+	"Project.SHA256":                {"description": "The checksum of the downloaded archive."},
+	"Project.Module":                {"description": "The Go module path of the project."},
+	"Project.Answers":               {"description": "The values of the template variables."},
+	"Project.DisableInlineCommands": {"description": "Disables the // $ command source code comments which are executed on run."},
+	"Project.NodePackageManager":    {"description": "The node package manager to execute the npm commands, defaults to npm.", "minLength": 1},
+	"Project.DisableNpmInstall":     {"description": "Disables the npm install on the first run and on package.json changes."},
+	"Project.NpmBuildScriptName":    {"description": "The package.json script to execute on run and on frontend changes, defaults to build."},
+	"Project.Watcher":               {"description": "The file watcher which rebuilds the project on changes."},
+	"Project.LiveReload":            {"description": "The browser live reload server."},
+	"Project.Profiles":              {"description": "The named environments of the project, selected by run --profile."},
+	"Project.Files":                 {"description": "The files and directories which were installed by the template."},
+	"Watcher.Disable":               {"description": "Disables the rebuild and the restart of the project on file changes."},
+	"Watcher.Backend":               {"description": "The file extensions which rebuild and restart the backend."},
+	"Watcher.Frontend":              {"description": "The file extensions which rebuild the frontend."},
+	"Watcher.IgnoreDirs":            {"description": "The patterns or root directories to ignore."},
+	"LiveReload.Disable":            {"description": "Disables the browser live reload."},
+	"LiveReload.Port":               {"description": "The port of the live reload server.", "minimum": 0, "maximum": math.MaxUint16},
+	"Profile.Env":                   {"description": "The environment variables, they override the ones of the env files."},
+	"Profile.EnvFiles":              {"description": "The environment files which are loaded after the .env one."},
+	"Profile.Tags":                  {"description": "The Go build tags of the project's executable."},
+}
+
+// schemaItemKeywords are the constraints of the elements of the project file's lists, by "Type.Field".
+var schemaItemKeywords = map[string]map[string]interface{}{
+	"Watcher.Backend":  {"pattern": `^\.[^/\\ ]+$`},
+	"Watcher.Frontend": {"pattern": `^\.[^/\\ ]+$`},
+	"Profile.Tags":     {"pattern": buildTagPattern.String()},
+}
+
+// Schema returns the JSON Schema of the project file of the "format", one of the `ProjectFormats`.
+// The field names depend on the format, editors use it to validate and autocomplete the project file.
+func Schema(format string) ([]byte, error) {
+	name, err := projectFilename(format)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := typeSchema(reflect.TypeOf(Project{}), formatTag(projectFileFormat(name)))
+	schema["$schema"] = SchemaURI
+	schema["title"] = name
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// typeSchema returns the JSON Schema of the "typ" with the struct fields encoded by the "tag", e.g. "yaml".
+func typeSchema(typ reflect.Type, tag string) map[string]interface{} {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			name := strings.Split(f.Tag.Get(tag), ",")[0]
+			if f.PkgPath != "" || name == "-" {
+				continue
+			}
+
+			if name == "" {
+				name = f.Name
+			}
+
+			prop := typeSchema(f.Type, tag)
+			key := typ.Name() + "." + f.Name
+			for k, v := range schemaKeywords[key] {
+				prop[k] = v
+			}
+
+			if items, ok := prop["items"].(map[string]interface{}); ok {
+				for k, v := range schemaItemKeywords[key] {
+					items[k] = v
+				}
+			}
+
+			properties[name] = prop
+		}
+
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeSchema(typ.Elem(), tag),
+		}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": typeSchema(typ.Elem(), tag),
+		}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		// Any value, e.g. the answers.
+		return map[string]interface{}{}
+	}
+}
@@ -0,0 +1,383 @@
+package project
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// Supported archive formats.
+const (
+	ArchiveZip   = ".zip"
+	ArchiveTarGz = ".tar.gz"
+)
+
+// Archive is the result of a `Source.Archive` call.
+type Archive struct {
+	// Body is the archive's contents, it should be closed by the caller.
+	Body io.ReadCloser
+	// Format is one of `ArchiveZip` or `ArchiveTarGz`.
+	Format string
+	// Commit is the resolved commit hash, if known by the source provider.
+	Commit string
+}
+
+// Source is the interface which a template source provider should implement
+// in order to fetch a project's archive, e.g. GitHub, GitLab, Gitea or a plain file server.
+type Source interface {
+	// Archive returns the compressed contents of "repo" at "version".
+	Archive(repo, version string) (*Archive, error)
+	// Versions returns the available versions (e.g. tags) of "repo", if any.
+	Versions(repo string) []string
+}
+
+// CommitResolver can be optionally implemented by a `Source`
+// to resolve the commit hash of a version without downloading its archive.
+type CommitResolver interface {
+	Commit(repo, version string) (string, error)
+}
+
+var sources = map[string]Source{
+	"github": githubSource{},
+	"gitlab": gitlabSource{},
+	"gitea":  giteaSource{},
+	"http":   httpSource{},
+	"https":  httpSource{},
+	"file":   fileSource{},
+}
+
+// RegisterSource registers or replaces a template source provider,
+// the "scheme" is the prefix of a `Project.Repo`, e.g. "gitlab" for "gitlab:owner/repo".
+func RegisterSource(scheme string, src Source) {
+	sources[scheme] = src
+}
+
+// ParseSource returns the source provider and the provider-specific repository of a "repo".
+// Examples:
+//
+//	owner/repo                         -> GitHub
+//	github:owner/repo                  -> GitHub
+//	gitlab:owner/repo                  -> gitlab.com
+//	gitlab:gitlab.example.com/owner/repo
+//	gitea:gitea.example.com/owner/repo
+//	https://example.com/templates/x.tar.gz
+//	file://./templates/x
+//	file://./templates/x.zip
+//	git+https://github.com/owner/repo.git
+//	git@github.com:owner/repo.git
+func ParseSource(repo string) (Source, string, error) {
+	if isSCPLike(repo) {
+		return DefaultGitSource, repo, nil
+	}
+
+	if idx := strings.Index(repo, "://"); idx > 0 {
+		scheme := repo[:idx]
+		src, ok := sources[scheme]
+		if !ok {
+			return nil, "", fmt.Errorf("unknown source provider: %s", scheme)
+		}
+
+		if scheme == "file" {
+			return src, repo[idx+3:], nil
+		}
+
+		return src, repo, nil
+	}
+
+	if idx := strings.IndexByte(repo, ':'); idx > 0 {
+		scheme := repo[:idx]
+		src, ok := sources[scheme]
+		if !ok {
+			return nil, "", fmt.Errorf("unknown source provider: %s", scheme)
+		}
+
+		return src, repo[idx+1:], nil
+	}
+
+	return sources["github"], repo, nil
+}
+
+// GitRepo returns the git+https form of a GitHub, GitLab or Gitea "repo",
+// so it can be cloned through the `GitSource` instead of downloading its archive.
+func GitRepo(repo string) (string, error) {
+	src, repo, err := ParseSource(repo)
+	if err != nil {
+		return "", err
+	}
+
+	var host string
+	switch src.(type) {
+	case githubSource:
+		host, repo = splitHost(repo, "github.com")
+	case gitlabSource:
+		host, repo = splitHost(repo, "gitlab.com")
+	case giteaSource:
+		host, repo = splitHost(repo, "gitea.com")
+	case *GitSource:
+		return repo, nil
+	default:
+		return "", fmt.Errorf("repository <%s> can not be cloned", repo)
+	}
+
+	return fmt.Sprintf("git+https://%s/%s.git", host, repo), nil
+}
+
+// ListVersions returns the available versions of "repo" based on its source provider.
+func ListVersions(repo string) []string {
+	repo, _ = SplitSubdir(repo)
+	src, repo, err := ParseSource(repo)
+	if err != nil {
+		return nil
+	}
+
+	return src.Versions(repo)
+}
+
+// archiveFormat returns the archive format based on the "name" extension.
+func archiveFormat(name string) string {
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return ArchiveTarGz
+	case strings.HasSuffix(name, ".zip"):
+		return ArchiveZip
+	default:
+		return ""
+	}
+}
+
+// splitHost returns the host of a "host/owner/repo" form
+// or "defaultHost" if the first part is not a domain name, e.g. "owner/repo".
+func splitHost(repo, defaultHost string) (string, string) {
+	if idx := strings.IndexByte(repo, '/'); idx > 0 && strings.Contains(repo[:idx], ".") {
+		return repo[:idx], repo[idx+1:]
+	}
+
+	return defaultHost, repo
+}
+
+func downloadArchive(archiveURL, format string) (*Archive, error) {
+	r, err := utils.DownloadReader(archiveURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Archive{Body: r, Format: format}, nil
+}
+
+func listTags(tagsURL string) []string {
+	resp := []struct {
+		Name string `json:"name"`
+	}{}
+
+	b, err := utils.Download(tagsURL, nil)
+	if err != nil {
+		return nil
+	}
+
+	if err = json.Unmarshal(b, &resp); err != nil {
+		return nil
+	}
+
+	tags := make([]string, 0, len(resp))
+	for _, v := range resp {
+		tags = append(tags, v.Name)
+	}
+
+	return tags
+}
+
+type githubSource struct{}
+
+func (githubSource) Archive(repo, version string) (*Archive, error) {
+	repo = strings.TrimPrefix(repo, "github.com/")
+	zipURL := fmt.Sprintf("https://github.com/%s/archive/%s.zip", repo, version) // e.g. https://github.com/kataras/iris-cli/archive/master.zip
+	return downloadArchive(zipURL, ArchiveZip)
+}
+
+// Commit implements the `CommitResolver` interface.
+func (githubSource) Commit(repo, version string) (string, error) {
+	repo = strings.TrimPrefix(repo, "github.com/")
+	commitURL := fmt.Sprintf("https://api.github.com/repos/%s/commits/%s", repo, url.PathEscape(version))
+	b, err := utils.Download(commitURL, nil, func(r *http.Request) error {
+		r.Header.Set("Accept", "application/vnd.github.sha")
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
+
+func (githubSource) Versions(repo string) []string {
+	repo = strings.TrimPrefix(repo, "github.com/")
+	return utils.ListReleases(repo)
+}
+
+type gitlabSource struct{}
+
+func (gitlabSource) Archive(repo, version string) (*Archive, error) {
+	host, repo := splitHost(repo, "gitlab.com")
+	// e.g. https://gitlab.com/owner/repo/-/archive/main/repo-main.zip
+	zipURL := fmt.Sprintf("https://%s/%s/-/archive/%s/%s-%s.zip", host, repo, version, path.Base(repo), strings.ReplaceAll(version, "/", "-"))
+	return downloadArchive(zipURL, ArchiveZip)
+}
+
+func (gitlabSource) Versions(repo string) []string {
+	host, repo := splitHost(repo, "gitlab.com")
+	return listTags(fmt.Sprintf("https://%s/api/v4/projects/%s/repository/tags", host, url.PathEscape(repo)))
+}
+
+type giteaSource struct{}
+
+func (giteaSource) Archive(repo, version string) (*Archive, error) {
+	host, repo := splitHost(repo, "gitea.com")
+	// e.g. https://gitea.com/owner/repo/archive/main.zip
+	zipURL := fmt.Sprintf("https://%s/%s/archive/%s.zip", host, repo, version)
+	return downloadArchive(zipURL, ArchiveZip)
+}
+
+func (giteaSource) Versions(repo string) []string {
+	host, repo := splitHost(repo, "gitea.com")
+	return listTags(fmt.Sprintf("https://%s/api/v1/repos/%s/tags", host, repo))
+}
+
+// httpSource fetches a plain .zip or .tar.gz archive, the version is ignored.
+type httpSource struct{}
+
+func (httpSource) Archive(repo, version string) (*Archive, error) {
+	format := archiveFormat(repo)
+	if format == "" {
+		return nil, fmt.Errorf("unknown archive format: %s", repo)
+	}
+
+	return downloadArchive(repo, format)
+}
+
+func (httpSource) Versions(repo string) []string {
+	return nil
+}
+
+// fileSource reads a local .zip or .tar.gz archive or a local directory, the version is ignored.
+type fileSource struct{}
+
+func (fileSource) Archive(repo, version string) (*Archive, error) {
+	name := filepath.FromSlash(repo)
+	if utils.IsDir(name) {
+		b, err := zipDir(name)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Archive{Body: utils.NoOpReadCloser(bytes.NewReader(b)), Format: ArchiveZip}, nil
+	}
+
+	format := archiveFormat(name)
+	if format == "" {
+		return nil, fmt.Errorf("unknown archive format: %s", repo)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Archive{Body: f, Format: format}, nil
+}
+
+func (fileSource) Versions(repo string) []string {
+	return nil
+}
+
+// zipDir compresses the "dir" directory, its files are placed under a root folder
+// named after the directory itself, as the remote archives do.
+func zipDir(dir string) ([]byte, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	root := filepath.Base(dir)
+
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+
+	err = filepath.Walk(dir, func(fpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, fpath)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		isLink := info.Mode()&os.ModeSymlink != 0
+		if !info.IsDir() && !info.Mode().IsRegular() && !isLink {
+			return nil // sockets, named pipes and devices.
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+
+		header.Name = path.Join(root, filepath.ToSlash(rel))
+		if info.IsDir() {
+			header.Name += "/"
+		} else {
+			header.Method = zip.Deflate
+		}
+
+		fw, err := w.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		if isLink {
+			// The target is the contents of a link entry, as the zip tool writes it.
+			target, err := os.Readlink(fpath)
+			if err != nil {
+				return err
+			}
+
+			_, err = io.WriteString(fw, filepath.ToSlash(target))
+			return err
+		}
+
+		f, err := os.Open(fpath)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(fw, f)
+		f.Close()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
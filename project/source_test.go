@@ -0,0 +1,179 @@
+package project
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		repo         string
+		expectedSrc  Source
+		expectedRepo string
+	}{
+		{"iris-contrib/basic-template", githubSource{}, "iris-contrib/basic-template"},
+		{"github:iris-contrib/basic-template", githubSource{}, "iris-contrib/basic-template"},
+		{"gitlab:owner/repo", gitlabSource{}, "owner/repo"},
+		{"gitea:gitea.example.com/owner/repo", giteaSource{}, "gitea.example.com/owner/repo"},
+		{"https://example.com/templates/basic.tar.gz", httpSource{}, "https://example.com/templates/basic.tar.gz"},
+		{"file://./templates/basic", fileSource{}, "./templates/basic"},
+	}
+
+	for i, tt := range tests {
+		src, repo, err := ParseSource(tt.repo)
+		if err != nil {
+			t.Fatalf("[%d] %v", i, err)
+		}
+
+		if src != tt.expectedSrc {
+			t.Fatalf("[%d] expected source: %T but got %T", i, tt.expectedSrc, src)
+		}
+
+		if repo != tt.expectedRepo {
+			t.Fatalf("[%d] expected repo: %q but got %q", i, tt.expectedRepo, repo)
+		}
+	}
+
+	if _, _, err := ParseSource("unknown:owner/repo"); err == nil {
+		t.Fatalf("expected error for unknown source provider")
+	}
+}
+
+func TestInstallTarGz(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	archiveFile := filepath.Join(dir, "basic.tar.gz")
+	err = ioutil.WriteFile(archiveFile, newTestTarGz(t, map[string]string{
+		"basic-main/":                 "",
+		"basic-main/go.mod":           "module github.com/iris-contrib/basic\n",
+		"basic-main/main.go":          "package main\n\nimport _ \"github.com/iris-contrib/basic/routes\"\n",
+		"basic-main/routes/routes.go": "package routes\n",
+	}), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Project{
+		Repo:   "file://" + filepath.ToSlash(archiveFile),
+		Dest:   filepath.Join(dir, "app"),
+		Module: "github.com/author/app",
+	}
+	if err = p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(p.Dest, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "package main\n\nimport _ \"github.com/author/app/routes\"\n", string(b); expected != got {
+		t.Fatalf("expected main.go contents:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	if expected, got := 3, len(p.Files); expected != got {
+		t.Fatalf("expected %d installed files but got %d: %v", expected, got, p.Files)
+	}
+}
+
+func newTestTarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+
+	// Write directories first, as the archive tools do.
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if name[len(name)-1] == '/' {
+			names = append([]string{name}, names...)
+		} else {
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		contents := files[name]
+		h := &tar.Header{Name: name, Mode: 0644, Size: int64(len(contents)), Typeflag: tar.TypeReg}
+		if name[len(name)-1] == '/' {
+			h.Mode = 0755
+			h.Typeflag = tar.TypeDir
+		}
+
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tw.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestInstallDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require privileges on windows")
+	}
+
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "basic")
+	writeTestFile(t, filepath.Join(src, "go.mod"), "module github.com/iris-contrib/basic\n")
+	writeTestFile(t, filepath.Join(src, "main.go"), "package main\n")
+	writeTestFile(t, filepath.Join(src, "web", "index.html"), "<html></html>\n")
+	if err = os.Symlink(filepath.Join("web", "index.html"), filepath.Join(src, "index.html")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Special files are not archived.
+	if l, err := net.Listen("unix", filepath.Join(src, "app.sock")); err == nil {
+		defer l.Close()
+	}
+
+	p := &Project{Repo: "file://" + filepath.ToSlash(src), Dest: filepath.Join(dir, "app"), NoVerify: true}
+	if err = p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := os.Readlink(filepath.Join(p.Dest, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "web/index.html", target; expected != got {
+		t.Fatalf("expected symbolic link target: %s but got: %s", expected, got)
+	}
+
+	if expected, got := "<html></html>\n", readTestFile(t, filepath.Join(p.Dest, "index.html")); expected != got {
+		t.Fatalf("expected linked contents: %q but got: %q", expected, got)
+	}
+
+	if _, err = os.Lstat(filepath.Join(p.Dest, "app.sock")); !os.IsNotExist(err) {
+		t.Fatalf("expected the socket to be skipped")
+	}
+}
@@ -0,0 +1,108 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kataras/iris-cli/utils"
+
+	"github.com/kataras/golog"
+)
+
+// StateDir is the project's directory of the machine-local files, it is git-ignored by itself.
+const StateDir = ".iris"
+
+// StateFilename is the slash-separated project file of the machine-local state:
+// the destination, the build files and whether the project is running.
+const StateFilename = StateDir + "/state.yml"
+
+// projectFiles are the files which are written by `SaveToDisk`,
+// they are kept on installation and restored on failures.
+var projectFiles = append([]string{StateFilename, LegacyProjectFilename}, ProjectFilenames...)
+
+// projectState is the machine-local part of a project, see `StateFilename`.
+type projectState struct {
+	Dest           string   `yaml:"Dest"`
+	BuildFiles     []string `yaml:"BuildFiles"`
+	MD5PackageJSON []byte   `yaml:"MD5PackageJSON"`
+	Running        bool     `yaml:"Running,omitempty"`
+}
+
+func (p *Project) state() *projectState {
+	return &projectState{
+		Dest:           p.Dest,
+		BuildFiles:     p.BuildFiles,
+		MD5PackageJSON: p.MD5PackageJSON,
+		Running:        p.Running,
+	}
+}
+
+// setState sets the local "state" of the project of the "projectPath" directory.
+// The destination defaults to the "projectPath", e.g. on a fresh clone of the project.
+func (p *Project) setState(state *projectState, projectPath string) {
+	p.Dest = state.Dest
+	if p.Dest == "" {
+		p.Dest = filepath.ToSlash(projectPath)
+	}
+
+	p.BuildFiles = state.BuildFiles
+	p.MD5PackageJSON = state.MD5PackageJSON
+	p.Running = state.Running
+}
+
+// SaveState writes the project's local state to the `StateFilename`,
+// the `ProjectFilename` is not modified.
+func (p *Project) SaveState() error {
+	dir := filepath.Join(p.Dest, StateDir)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	// Ignore the whole directory, so the state is never committed.
+	if ignoreFile := filepath.Join(dir, ".gitignore"); !utils.Exists(ignoreFile) {
+		if err := os.WriteFile(ignoreFile, []byte("*\n"), 0644); err != nil {
+			return err
+		}
+	}
+
+	return utils.Export(filepath.Join(p.Dest, filepath.FromSlash(StateFilename)), p.state())
+}
+
+// migrateLegacyProject splits the `LegacyProjectFilename` of the "projectPath" directory
+// to the `ProjectFilename` and the `StateFilename` and removes it.
+func migrateLegacyProject(projectPath string) (*Project, error) {
+	legacyFile := filepath.Join(projectPath, LegacyProjectFilename)
+
+	p := new(Project)
+	if err := utils.Import(legacyFile, p); err != nil {
+		return nil, err
+	}
+
+	state := new(projectState)
+	if err := utils.Import(legacyFile, state); err != nil {
+		return nil, err
+	}
+	// The files are written next to the legacy one, even if the project was moved.
+	state.Dest = ""
+	p.setState(state, projectPath)
+
+	p.setDefaults()
+	if err := p.SaveToDisk(); err != nil {
+		return nil, err
+	}
+
+	if err := os.Remove(legacyFile); err != nil {
+		return nil, err
+	}
+
+	golog.Infof("Project file <%s> migrated to <%s> and <%s>, commit the %s file and remove the %s entry of your .gitignore",
+		LegacyProjectFilename, ProjectFilename, StateFilename, ProjectFilename, LegacyProjectFilename)
+	return p, nil
+}
+
+// isProjectFile reports whether the slash-separated "name", relative to the project's directory,
+// is one of the project files, which are ignored by the build and the watcher.
+func isProjectFile(name string) bool {
+	return containsString(ProjectFilenames, name) || name == LegacyProjectFilename || name == StateDir || strings.HasPrefix(name, StateDir+"/")
+}
@@ -0,0 +1,171 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestLoadFromDiskState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Legacy project file, migrated on load.
+	writeTestFile(t, filepath.Join(dir, LegacyProjectFilename), `Name: app
+Repo: iris-contrib/app
+Dest: /moved/app
+Watcher:
+  IgnoreDirs: [node_modules]
+BuildFiles: [app.exe, public/build]
+Running: true
+`)
+
+	p, err := LoadFromDisk(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := filepath.ToSlash(dir), p.Dest; expected != got {
+		t.Fatalf("expected dest: %s but got: %s", expected, got)
+	}
+
+	if expected, got := []string{"node_modules"}, p.Watcher.IgnoreDirs; !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected ignore dirs: %v but got: %v", expected, got)
+	}
+
+	if expected, got := []string{"app.exe", "public/build"}, p.BuildFiles; !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected build files: %v but got: %v", expected, got)
+	}
+
+	if !p.Running {
+		t.Fatalf("expected running state to be migrated")
+	}
+
+	if _, err = os.Stat(filepath.Join(dir, LegacyProjectFilename)); !os.IsNotExist(err) {
+		t.Fatalf("expected the legacy project file to be removed")
+	}
+
+	config := readTestFile(t, filepath.Join(dir, ProjectFilename))
+	for _, field := range []string{"Dest:", "BuildFiles:", "Running:", "MD5PackageJSON:"} {
+		if strings.Contains(config, field) {
+			t.Fatalf("expected %s to not contain the local state field %s:\n%s", ProjectFilename, field, config)
+		}
+	}
+
+	if !strings.Contains(config, "node_modules") {
+		t.Fatalf("expected %s to contain the watcher configuration:\n%s", ProjectFilename, config)
+	}
+
+	state := readTestFile(t, filepath.Join(dir, filepath.FromSlash(StateFilename)))
+	if !strings.Contains(state, "public/build") {
+		t.Fatalf("expected %s to contain the build files:\n%s", StateFilename, state)
+	}
+
+	if expected, got := "*\n", readTestFile(t, filepath.Join(dir, StateDir, ".gitignore")); expected != got {
+		t.Fatalf("expected the state directory to be git-ignored but got: %q", got)
+	}
+
+	// A fresh clone has the configuration only.
+	clone := filepath.Join(dir, "clone")
+	writeTestFile(t, filepath.Join(clone, ProjectFilename), config)
+
+	p, err = LoadFromDisk(clone)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := filepath.ToSlash(clone), p.Dest; expected != got {
+		t.Fatalf("expected dest: %s but got: %s", expected, got)
+	}
+
+	if len(p.BuildFiles) > 0 || p.Running {
+		t.Fatalf("expected an empty local state but got build files: %v and running: %v", p.BuildFiles, p.Running)
+	}
+
+	if expected, got := "iris-contrib/app", p.Repo; expected != got {
+		t.Fatalf("expected repo: %s but got: %s", expected, got)
+	}
+}
+
+func TestProjectConvert(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTestFile(t, filepath.Join(dir, ProjectFilename), `Name: app
+Repo: iris-contrib/app
+Version: v1.0.0
+Module: github.com/author/app
+Answers:
+  author: kataras
+  port: 8080
+  docker: true
+  ratio: 0.5
+Files: [go.mod, main.go]
+Watcher:
+  IgnoreDirs: [node_modules]
+LiveReload:
+  Port: 35730
+`)
+
+	p, err := LoadFromDisk(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := yaml.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []string{"toml", "json", "yml"} {
+		name, err := p.Convert(format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		if expected, got := "iris."+format, name; expected != got {
+			t.Fatalf("expected project file: %s but got: %s", expected, got)
+		}
+
+		if found, err := findProjectFile(dir); err != nil || found != name {
+			t.Fatalf("%s: expected the previous project file to be removed but got: %s (%v)", format, found, err)
+		}
+
+		if p, err = LoadFromDisk(dir); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		if expected, got := name, p.ConfigFile(); expected != got {
+			t.Fatalf("expected the format to be detected from the file: %s but got: %s", expected, got)
+		}
+
+		got, err := yaml.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(expected) != string(got) {
+			t.Fatalf("%s: expected:\n%s\nbut got:\n%s", format, expected, got)
+		}
+	}
+
+	if _, err = p.Convert("xml"); err == nil {
+		t.Fatalf("expected an error on unknown format")
+	}
+
+	writeTestFile(t, filepath.Join(dir, "iris.json"), "{}")
+	if _, err = LoadFromDisk(dir); err == nil {
+		t.Fatalf("expected an error on more than one project file")
+	}
+}
@@ -0,0 +1,166 @@
+package project
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/kataras/iris-cli/parser"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// SplitSubdir splits a "repo" of the form "owner/repo//path/to/dir"
+// to its repository and its slash-separated subdirectory, e.g. "owner/repo" and "path/to/dir".
+// The subdirectory is empty when the whole repository should be installed.
+func SplitSubdir(repo string) (string, string) {
+	start := 0
+	if idx := strings.Index(repo, "://"); idx != -1 {
+		start = idx + 3
+	}
+
+	idx := strings.Index(repo[start:], "//")
+	if idx == -1 {
+		return repo, ""
+	}
+	idx += start
+
+	subdir := strings.Trim(path.Clean("/"+repo[idx+2:]), "/")
+	return repo[:idx], subdir
+}
+
+// JoinSubdir returns the "repo//subdir" form of a repository's subdirectory.
+func JoinSubdir(repo, subdir string) string {
+	if subdir == "" {
+		return repo
+	}
+
+	return repo + "//" + subdir
+}
+
+// findArchiveFile returns the regular archive file of the slash-separated "name" or nil.
+func findArchiveFile(files []*archiveFile, name string) *archiveFile {
+	// Starting from the end because the list is usually sorted alphabetically
+	// and the root files are more likely to be visible at the end.
+	for i := len(files) - 1; i >= 0; i-- {
+		if f := files[i]; f.Mode.IsRegular() && path.Clean(f.Name) == name {
+			return f
+		}
+	}
+
+	return nil
+}
+
+func readArchiveFile(f *archiveFile, maxSize int64) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return ioutil.ReadAll(io.LimitReader(rc, maxSize))
+}
+
+// archiveModule describes the nearest go.mod file of an archive's subdirectory.
+type archiveModule struct {
+	Path    string // the module path of the subdirectory.
+	Dir     string // the directory of the go.mod file, relative to the archive's root.
+	ModFile []byte
+	SumFile []byte
+}
+
+// findModule finds the nearest go.mod file of the "subdir" of the archive's "root" folder.
+// It returns nil if no go.mod file exists in the subdirectory and its parents.
+func findModule(files []*archiveFile, root, subdir string) (*archiveModule, error) {
+	dir := subdir
+	for {
+		if f := findArchiveFile(files, path.Join(root, dir, "go.mod")); f != nil {
+			b, err := readArchiveFile(f, 1<<20)
+			if err != nil {
+				return nil, err
+			}
+
+			modulePath := string(parser.ModulePath(b))
+			if rel := strings.Trim(strings.TrimPrefix(subdir, dir), "/"); rel != "" && dir != subdir {
+				modulePath = path.Join(modulePath, rel)
+			}
+
+			m := &archiveModule{Path: modulePath, Dir: dir, ModFile: b}
+			if f = findArchiveFile(files, path.Join(root, dir, "go.sum")); f != nil {
+				if m.SumFile, err = readArchiveFile(f, 16<<20); err != nil {
+					return nil, err
+				}
+			}
+
+			return m, nil
+		}
+
+		if dir == "" || dir == "." {
+			return nil, nil
+		}
+
+		if dir = path.Dir(dir); dir == "." {
+			dir = ""
+		}
+	}
+}
+
+// synthesizeGoMod returns a go.mod file for the "modulePath" of a subdirectory
+// which has no go.mod file, based on its parent "m" module.
+// The parent's requirements are copied and the parent module itself is required at "version",
+// if "version" is a semantic version tag.
+func synthesizeGoMod(m *archiveModule, modulePath, version string) ([]byte, error) {
+	f := new(modfile.File)
+	if err := f.AddModuleStmt(modulePath); err != nil {
+		return nil, err
+	}
+
+	if m == nil {
+		return f.Format()
+	}
+
+	parent, err := modfile.Parse("go.mod", m.ModFile, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s/go.mod: %v", m.Dir, err)
+	}
+
+	if parent.Go != nil {
+		if err = f.AddGoStmt(parent.Go.Version); err != nil {
+			return nil, err
+		}
+	}
+
+	if parent.Module != nil && semver.IsValid(version) {
+		f.AddNewRequire(parent.Module.Mod.Path, version, false)
+	}
+
+	for _, r := range parent.Require {
+		f.AddNewRequire(r.Mod.Path, r.Mod.Version, r.Indirect)
+	}
+
+	for _, r := range parent.Replace {
+		if r.New.Version == "" {
+			continue // local directories are not installed.
+		}
+
+		if err = f.AddReplace(r.Old.Path, r.Old.Version, r.New.Path, r.New.Version); err != nil {
+			return nil, err
+		}
+	}
+
+	f.Cleanup()
+	return f.Format()
+}
+
+func containsString(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
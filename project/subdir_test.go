@@ -0,0 +1,132 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitSubdir(t *testing.T) {
+	tests := []struct {
+		repo           string
+		expectedRepo   string
+		expectedSubdir string
+	}{
+		{"kataras/iris", "kataras/iris", ""},
+		{"kataras/iris//_examples/mvc/basic", "kataras/iris", "_examples/mvc/basic"},
+		{"kataras/iris//_examples/mvc/", "kataras/iris", "_examples/mvc"},
+		{"gitlab:owner/repo//sub", "gitlab:owner/repo", "sub"},
+		{"https://files.example.com/basic.zip", "https://files.example.com/basic.zip", ""},
+		{"https://files.example.com/basic.zip//sub/dir", "https://files.example.com/basic.zip", "sub/dir"},
+		{"kataras/iris//../../etc", "kataras/iris", "etc"},
+	}
+
+	for i, tt := range tests {
+		repo, subdir := SplitSubdir(tt.repo)
+		if repo != tt.expectedRepo || subdir != tt.expectedSubdir {
+			t.Fatalf("[%d] expected: %q, %q but got: %q, %q", i, tt.expectedRepo, tt.expectedSubdir, repo, subdir)
+		}
+	}
+}
+
+func TestRegistryResolveSubdirVersion(t *testing.T) {
+	reg := NewRegistry()
+	reg.Projects = map[string]*RegistryProject{
+		"mvc":    {Repo: "kataras/iris//_examples/mvc@v12.2.0", DefaultBranch: "master"},
+		"basic":  {Repo: "kataras/iris//_examples/basic", DefaultBranch: "master"},
+		"hello":  {Repo: "kataras/iris//_examples/hello@v12.2.0"},
+		"latest": {Repo: "kataras/iris@v12.2.0"},
+	}
+
+	tests := []struct {
+		name, version   string
+		expectedRepo    string
+		expectedVersion string
+	}{
+		{"mvc", "", "kataras/iris//_examples/mvc", "v12.2.0"},
+		{"mvc", "latest", "kataras/iris//_examples/mvc", "v12.2.0"},
+		{"mvc", "v12.2.11", "kataras/iris//_examples/mvc", "v12.2.11"},
+		{"basic", "", "kataras/iris//_examples/basic", "master"},
+		{"hello", "", "kataras/iris//_examples/hello", "v12.2.0"},
+		{"latest", "", "kataras/iris", "v12.2.0"},
+	}
+
+	for _, tt := range tests {
+		p := &Project{Name: tt.name, Version: tt.version}
+		if err := reg.Resolve(p); err != nil {
+			t.Fatalf("%s@%s: %v", tt.name, tt.version, err)
+		}
+
+		if p.Repo != tt.expectedRepo || p.Version != tt.expectedVersion {
+			t.Fatalf("%s@%s: expected repo: %s and version: %s but got: %s and %s", tt.name, tt.version, tt.expectedRepo, tt.expectedVersion, p.Repo, p.Version)
+		}
+	}
+
+	if repo, ok := reg.Exists("mvc"); !ok || repo != "kataras/iris//_examples/mvc" {
+		t.Fatalf("expected the repository without its version but got: %s", repo)
+	}
+}
+
+func TestUnzipSubdir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	body := newTestZip(t,
+		testZipEntry{Name: "app-main/go.sum", Contents: "github.com/kataras/iris/v12 v12.2.11 h1:sum=\n", Mode: 0644},
+		testZipEntry{Name: "app-main/main.go", Contents: "package main", Mode: 0644},
+		testZipEntry{Name: "app-main/_examples/", Mode: os.ModeDir | 0755},
+		testZipEntry{Name: "app-main/_examples/mvc/", Mode: os.ModeDir | 0755},
+		testZipEntry{Name: "app-main/_examples/mvc/main.go", Contents: "package main\n\nimport _ \"github.com/author/app/_examples/mvc/controllers\"\n", Mode: 0644},
+		testZipEntry{Name: "app-main/_examples/mvc/controllers/controllers.go", Contents: "package controllers\n", Mode: 0644},
+		testZipEntry{Name: "app-main/_examples/grpc/go.mod", Contents: "module github.com/author/grpc-example\n", Mode: 0644},
+		testZipEntry{Name: "app-main/_examples/grpc/main.go", Contents: "package main", Mode: 0644},
+	)
+
+	// Subdirectory without a go.mod file.
+	p := &Project{Repo: "author/app//_examples/mvc", Version: "v1.0.0", Dest: filepath.Join(dir, "mvc"), Module: "github.com/me/mvc"}
+	oldModuleName, err := p.unzip(body, ArchiveZip, p.Dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "github.com/author/app/_examples/mvc", string(oldModuleName); expected != got {
+		t.Fatalf("expected old module name: %q but got: %q", expected, got)
+	}
+
+	expectedFiles := []string{"main.go", "controllers/controllers.go", "go.mod", "go.sum"}
+	if strings.Join(expectedFiles, ",") != strings.Join(p.Files, ",") {
+		t.Fatalf("expected files: %v but got: %v", expectedFiles, p.Files)
+	}
+
+	goMod := readTestFile(t, filepath.Join(p.Dest, "go.mod"))
+	for _, s := range []string{"module github.com/author/app/_examples/mvc\n", "require github.com/author/app v1.0.0\n"} {
+		if !strings.Contains(goMod, s) {
+			t.Fatalf("expected synthesized go.mod to contain: %q but got:\n%s", s, goMod)
+		}
+	}
+
+	// Subdirectory with its own go.mod file.
+	p = &Project{Repo: "author/app//_examples/grpc", Dest: filepath.Join(dir, "grpc")}
+	if oldModuleName, err = p.unzip(body, ArchiveZip, p.Dest); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "github.com/author/grpc-example", string(oldModuleName); expected != got {
+		t.Fatalf("expected old module name: %q but got: %q", expected, got)
+	}
+
+	if expected, got := 2, len(p.Files); expected != got {
+		t.Fatalf("expected %d files but got: %v", expected, p.Files)
+	}
+
+	// Missing subdirectory.
+	p = &Project{Repo: "author/app//_examples/missing", Dest: filepath.Join(dir, "missing")}
+	if _, err = p.unzip(body, ArchiveZip, p.Dest); err == nil {
+		t.Fatalf("expected an error for a missing subdirectory")
+	}
+}
@@ -0,0 +1,412 @@
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/kataras/iris-cli/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateFilename is the optional manifest file of a project template,
+// it is located at the root of the project's repository and it is not installed.
+const TemplateFilename = ".iris-template.yml"
+
+// Template variable types.
+const (
+	VarString = "string"
+	VarBool   = "bool"
+	VarInt    = "int"
+	VarChoice = "choice"
+)
+
+// Template is the manifest of a project template.
+// It declares the variables which are asked on installation,
+// their answers are used to render the file and directory names
+// and the contents of the files which match the `RenderFiles` patterns.
+//
+// Example:
+//
+//	Variables:
+//	  - Name: author
+//	    Prompt: Author name
+//	    GitConfig: user.name
+//	  - Name: port
+//	    Type: int
+//	    Default: 8080
+//	  - Name: docker
+//	    Type: bool
+//	Delims: ["[[", "]]"]
+//	RenderFiles: ["*.go", "cmd", "Dockerfile"]
+//	CopyWithoutRender: ["web/public/*"]
+//	Conditions:
+//	  Dockerfile: docker
+//	  db/postgres: database == "postgres"
+type Template struct {
+	Variables []*TemplateVariable `json:"variables" yaml:"Variables" toml:"Variables"`
+	// Delims are the left and right template action delimiters.
+	// Defaults to "{{" and "}}".
+	Delims []string `json:"delims,omitempty" yaml:"Delims" toml:"Delims"`
+	// RenderFiles is a list of glob patterns of files whose contents are rendered,
+	// other files are installed as they are.
+	RenderFiles []string `json:"render_files,omitempty" yaml:"RenderFiles" toml:"RenderFiles"`
+	// CopyWithoutRender is a list of glob patterns of files to be installed as they are,
+	// even if they match a `RenderFiles` pattern.
+	CopyWithoutRender []string `json:"copy_without_render,omitempty" yaml:"CopyWithoutRender" toml:"CopyWithoutRender"`
+	// Conditions maps glob patterns to boolean expressions over the variables,
+	// the matching files and directories are installed only when all of their expressions are true.
+	Conditions map[string]string `json:"conditions,omitempty" yaml:"Conditions" toml:"Conditions"`
+}
+
+// TemplateVariable is a template manifest's variable.
+type TemplateVariable struct {
+	Name string `json:"name" yaml:"Name" toml:"Name"`
+	// Type is one of "string", "bool", "int" and "choice".
+	// Defaults to "string".
+	Type    string      `json:"type,omitempty" yaml:"Type" toml:"Type"`
+	Default interface{} `json:"default,omitempty" yaml:"Default" toml:"Default"`
+	// Prompt is the question's message. Defaults to the Name.
+	Prompt string `json:"prompt,omitempty" yaml:"Prompt" toml:"Prompt"`
+	Help   string `json:"help,omitempty" yaml:"Help" toml:"Help"`
+	// Options are the available values of a "choice" variable.
+	Options  []string `json:"options,omitempty" yaml:"Options" toml:"Options"`
+	Required bool     `json:"required,omitempty" yaml:"Required" toml:"Required"`
+	// Pattern is a regular expression which the value of a "string" variable should match.
+	Pattern string `json:"pattern,omitempty" yaml:"Pattern" toml:"Pattern"`
+	// GitConfig is a git configuration key, e.g. "user.name" or "user.email",
+	// its value is used as the default value of the variable.
+	GitConfig string `json:"git_config,omitempty" yaml:"GitConfig" toml:"GitConfig"`
+}
+
+// ParseTemplate decodes a template manifest.
+func ParseTemplate(b []byte) (*Template, error) {
+	t := new(Template)
+	if err := yaml.Unmarshal(b, t); err != nil {
+		return nil, fmt.Errorf("template: %s: %v", TemplateFilename, err)
+	}
+
+	if len(t.Delims) != 0 && len(t.Delims) != 2 {
+		return nil, fmt.Errorf("template: %s: delims: expected left and right delimiters", TemplateFilename)
+	}
+
+	for _, v := range t.Variables {
+		if v.Name == "" {
+			return nil, fmt.Errorf("template: %s: variable name is missing", TemplateFilename)
+		}
+
+		switch v.Type {
+		case "":
+			v.Type = VarString
+		case VarString, VarBool, VarInt:
+		case VarChoice:
+			if len(v.Options) == 0 {
+				return nil, fmt.Errorf("template: %s: variable <%s>: options are missing", TemplateFilename, v.Name)
+			}
+		default:
+			return nil, fmt.Errorf("template: %s: variable <%s>: unknown type: %s", TemplateFilename, v.Name, v.Type)
+		}
+
+		if v.Prompt == "" {
+			v.Prompt = v.Name
+		}
+	}
+
+	for _, patterns := range [][]string{t.RenderFiles, t.CopyWithoutRender} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("template: %s: %s: %v", TemplateFilename, pattern, err)
+			}
+		}
+	}
+
+	for pattern, expr := range t.Conditions {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("template: %s: conditions: %s: %v", TemplateFilename, pattern, err)
+		}
+
+		if _, err := parseCondition(expr); err != nil {
+			return nil, fmt.Errorf("template: %s: conditions: %s: %v", TemplateFilename, pattern, err)
+		}
+	}
+
+	return t, nil
+}
+
+// DefaultValue returns the default value of the variable,
+// the git configuration value takes precedence.
+func (v *TemplateVariable) DefaultValue() interface{} {
+	if v.GitConfig != "" {
+		if value := utils.GitConfig(v.GitConfig); value != "" {
+			return value
+		}
+	}
+
+	if v.Default == nil {
+		switch v.Type {
+		case VarBool:
+			return false
+		case VarInt:
+			return 0
+		case VarChoice:
+			return v.Options[0]
+		default:
+			return ""
+		}
+	}
+
+	return v.Default
+}
+
+// Parse converts and validates an answer, e.g. a string input, to the variable's type.
+func (v *TemplateVariable) Parse(value interface{}) (interface{}, error) {
+	s := strings.TrimSpace(fmt.Sprintf("%v", value))
+
+	if s == "" && v.Required {
+		return nil, fmt.Errorf("variable <%s> is required", v.Name)
+	}
+
+	switch v.Type {
+	case VarBool:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+
+		if s == "" {
+			return false, nil
+		}
+
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("variable <%s>: expected a boolean value but got: %q", v.Name, s)
+		}
+		return b, nil
+	case VarInt:
+		if s == "" {
+			return 0, nil
+		}
+
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("variable <%s>: expected an integer value but got: %q", v.Name, s)
+		}
+		return n, nil
+	case VarChoice:
+		for _, option := range v.Options {
+			if option == s {
+				return s, nil
+			}
+		}
+
+		return nil, fmt.Errorf("variable <%s>: expected one of %s but got: %q", v.Name, strings.Join(v.Options, ", "), s)
+	default:
+		if v.Pattern != "" && s != "" {
+			matched, err := regexp.MatchString(v.Pattern, s)
+			if err != nil {
+				return nil, fmt.Errorf("variable <%s>: pattern: %v", v.Name, err)
+			}
+
+			if !matched {
+				return nil, fmt.Errorf("variable <%s>: %q does not match the pattern: %s", v.Name, s, v.Pattern)
+			}
+		}
+
+		return s, nil
+	}
+}
+
+// Answers validates the "answers" and fills the missing ones with their default values.
+func (t *Template) Answers(answers map[string]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(t.Variables))
+	for _, v := range t.Variables {
+		value, ok := answers[v.Name]
+		if !ok {
+			value = v.DefaultValue()
+		}
+
+		parsed, err := v.Parse(value)
+		if err != nil {
+			return nil, err
+		}
+
+		result[v.Name] = parsed
+	}
+
+	return result, nil
+}
+
+var templateFuncs = template.FuncMap{
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"title":   strings.Title,
+	"trim":    strings.TrimSpace,
+	"replace": strings.ReplaceAll,
+}
+
+func (t *Template) parse(name, text string) (*template.Template, error) {
+	tmpl := template.New(name).Funcs(templateFuncs).Option("missingkey=error")
+	if len(t.Delims) == 2 {
+		tmpl = tmpl.Delims(t.Delims[0], t.Delims[1])
+	}
+
+	return tmpl.Parse(text)
+}
+
+func (t *Template) execute(name, text string, data map[string]interface{}) (string, error) {
+	tmpl, err := t.parse(name, text)
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+	if err = tmpl.Execute(buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// Include reports whether the file or directory of the rendered "name" should be installed,
+// based on the `Conditions` which match the "name" or any of its parent directories.
+func (t *Template) Include(name string, data map[string]interface{}) (bool, error) {
+	for pattern, expr := range t.Conditions {
+		if !matchPath(pattern, name) {
+			continue
+		}
+
+		ok, err := evalCondition(expr, data)
+		if err != nil {
+			return false, fmt.Errorf("template: %s: condition of %s: %v", TemplateFilename, pattern, err)
+		}
+
+		if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// RenderName renders the segments of a slash-separated file or directory "name".
+// It reports false if a segment is rendered as empty, so the file should be skipped.
+func (t *Template) RenderName(name string, data map[string]interface{}) (string, bool, error) {
+	left := "{{"
+	if len(t.Delims) == 2 {
+		left = t.Delims[0]
+	}
+
+	if !strings.Contains(name, left) {
+		return name, true, nil
+	}
+
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		rendered, err := t.execute(name, segment, data)
+		if err != nil {
+			return "", false, err
+		}
+
+		if rendered = strings.TrimSpace(rendered); rendered == "" {
+			return "", false, nil
+		}
+
+		segments[i] = rendered
+	}
+
+	return path.Join(segments...), true, nil
+}
+
+// Render renders the "contents" of the "name" file if it matches a `RenderFiles` pattern.
+// Binary files and files matching the `CopyWithoutRender` patterns are returned as they are.
+func (t *Template) Render(name string, contents []byte, data map[string]interface{}) ([]byte, error) {
+	if !t.renders(name) || bytes.IndexByte(contents, 0) != -1 { // binary.
+		return contents, nil
+	}
+
+	rendered, err := t.execute(name, string(contents), data)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(rendered), nil
+}
+
+func (t *Template) renders(name string) bool {
+	for _, pattern := range t.CopyWithoutRender {
+		if matchPath(pattern, name) {
+			return false
+		}
+	}
+
+	for _, pattern := range t.RenderFiles {
+		if matchPath(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPath reports whether the "pattern" matches the slash-separated "name"
+// or any of its parent directories.
+func matchPath(pattern, name string) bool {
+	for name != "." && name != "/" && name != "" {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+
+		name = path.Dir(name)
+	}
+
+	return false
+}
+
+// loadTemplate parses the template manifest of the archive "files", if exists,
+// and fills the project's answers through its `Prompt`.
+func (p *Project) loadTemplate(files []*archiveFile, root string) (*Template, error) {
+	manifestFile := root + TemplateFilename
+
+	for _, f := range files {
+		if f.Name != manifestFile || !f.Mode.IsRegular() {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := ioutil.ReadAll(io.LimitReader(rc, 1<<20))
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		t, err := ParseTemplate(b)
+		if err != nil {
+			return nil, err
+		}
+
+		var answers map[string]interface{}
+		if p.Prompt != nil {
+			answers, err = p.Prompt(t, p.Answers)
+		} else {
+			answers, err = t.Answers(p.Answers)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		p.Answers = answers
+		return t, nil
+	}
+
+	return nil, nil
+}
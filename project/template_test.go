@@ -0,0 +1,129 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnzipTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := `Variables:
+  - Name: name
+    Required: true
+  - Name: port
+    Type: int
+    Default: 8080
+  - Name: docker
+    Type: bool
+RenderFiles: ["*.go", "cmd", "public"]
+CopyWithoutRender: ["public/*"]
+Conditions:
+  cmd/myapp/docker.go: docker
+`
+
+	body := newTestZip(t,
+		testZipEntry{Name: "app-main/" + TemplateFilename, Contents: manifest, Mode: 0644},
+		testZipEntry{Name: "app-main/main.go", Contents: `// {{.name}} listens on :{{.port}}`, Mode: 0644},
+		testZipEntry{Name: "app-main/cmd/{{.name}}/main.go", Contents: "package main", Mode: 0644},
+		testZipEntry{Name: "app-main/{{if .docker}}Dockerfile{{end}}", Contents: "FROM golang", Mode: 0644},
+		testZipEntry{Name: "app-main/cmd/{{.name}}/docker.go", Contents: "package main", Mode: 0644},
+		testZipEntry{Name: "app-main/public/index.html", Contents: "{{.raw}}", Mode: 0644},
+		testZipEntry{Name: "app-main/views/index.html", Contents: "<title>{{ .Title }}</title>", Mode: 0644},
+	)
+
+	p := &Project{Dest: filepath.Join(dir, "app"), Answers: map[string]interface{}{"name": "myapp"}}
+	if _, err = p.unzip(body, ArchiveZip, p.Dest); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "// myapp listens on :8080", readTestFile(t, filepath.Join(p.Dest, "main.go")); expected != got {
+		t.Fatalf("expected rendered contents: %q but got: %q", expected, got)
+	}
+
+	if expected, got := "{{.raw}}", readTestFile(t, filepath.Join(p.Dest, "public", "index.html")); expected != got {
+		t.Fatalf("expected copied contents: %q but got: %q", expected, got)
+	}
+
+	if expected, got := "<title>{{ .Title }}</title>", readTestFile(t, filepath.Join(p.Dest, "views", "index.html")); expected != got {
+		t.Fatalf("expected not rendered contents: %q but got: %q", expected, got)
+	}
+
+	if _, err = os.Stat(filepath.Join(p.Dest, "cmd", "myapp", "main.go")); err != nil {
+		t.Fatalf("expected rendered file name: %v", err)
+	}
+
+	for _, name := range []string{"Dockerfile", TemplateFilename, "cmd/myapp/docker.go"} {
+		if _, err = os.Stat(filepath.Join(p.Dest, name)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be skipped", name)
+		}
+	}
+
+	if expected, got := 8080, p.Answers["port"]; expected != got {
+		t.Fatalf("expected default answer: %v but got: %v", expected, got)
+	}
+
+	// Missing required variable.
+	p = &Project{Dest: filepath.Join(dir, "app2")}
+	if _, err = p.unzip(body, ArchiveZip, p.Dest); err == nil {
+		t.Fatalf("expected an error for the missing required variable")
+	}
+}
+
+func TestUnzipTemplateConditions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := `Variables:
+  - Name: docker
+    Type: bool
+  - Name: database
+    Type: choice
+    Options: [sqlite, postgres]
+Conditions:
+  Dockerfile: docker
+  db/postgres: database == "postgres"
+  db/sqlite: database == "sqlite" && !docker
+`
+
+	body := newTestZip(t,
+		testZipEntry{Name: "app-main/" + TemplateFilename, Contents: manifest, Mode: 0644},
+		testZipEntry{Name: "app-main/Dockerfile", Contents: "FROM golang", Mode: 0644},
+		testZipEntry{Name: "app-main/db/", Mode: os.ModeDir | 0755},
+		testZipEntry{Name: "app-main/db/postgres/", Mode: os.ModeDir | 0755},
+		testZipEntry{Name: "app-main/db/postgres/db.go", Contents: "package postgres", Mode: 0644},
+		testZipEntry{Name: "app-main/db/sqlite/db.go", Contents: "package sqlite", Mode: 0644},
+	)
+
+	p := &Project{Dest: filepath.Join(dir, "app"), Answers: map[string]interface{}{"docker": true, "database": "postgres"}}
+	if _, err = p.unzip(body, ArchiveZip, p.Dest); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"go.mod", "Dockerfile", "db", "db/postgres", "db/postgres/db.go"}
+	if len(p.Files) != len(expected) {
+		t.Fatalf("expected files: %v but got: %v", expected, p.Files)
+	}
+	for i := range expected {
+		if expected[i] != p.Files[i] {
+			t.Fatalf("expected files: %v but got: %v", expected, p.Files)
+		}
+	}
+
+	if _, err = os.Stat(filepath.Join(p.Dest, "db", "sqlite")); !os.IsNotExist(err) {
+		t.Fatalf("expected db/sqlite to be skipped")
+	}
+
+	if _, err = ParseTemplate([]byte("Conditions:\n  Dockerfile: docker &&\n")); err == nil {
+		t.Fatalf("expected an error for an invalid condition")
+	}
+}
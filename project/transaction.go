@@ -0,0 +1,289 @@
+package project
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kataras/golog"
+)
+
+// transaction moves the staged files of a project into its destination directory.
+// Any destination files which are overwritten are backed up first,
+// so a failure on any installation step can be reverted through `rollback`.
+type transaction struct {
+	dest      string
+	backupDir string
+
+	createdDest bool
+	journal     []*transactionEntry
+}
+
+type transactionEntry struct {
+	name        string // the created file or directory.
+	backedUp    bool   // the previous file is moved under the backup directory.
+	renamedFrom string // the existing file which is renamed to "name", see `rename`.
+}
+
+func newTransaction(dest string) (*transaction, error) {
+	tx := &transaction{dest: dest}
+
+	if _, err := os.Stat(dest); os.IsNotExist(err) {
+		if err = os.MkdirAll(dest, os.ModePerm); err != nil {
+			return nil, err
+		}
+		tx.createdDest = true
+	}
+
+	backupDir, err := tempDir(dest, ".iris-backup-")
+	if err != nil {
+		tx.rollback()
+		return nil, err
+	}
+	tx.backupDir = backupDir
+
+	return tx, nil
+}
+
+func (tx *transaction) path(name string) string {
+	return filepath.Join(tx.dest, filepath.FromSlash(name))
+}
+
+// backup moves an existing "name" destination file under the backup directory.
+// It reports whether a file existed.
+func (tx *transaction) backup(name string) (bool, error) {
+	fpath := tx.path(name)
+	if _, err := os.Lstat(fpath); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	backupPath := filepath.Join(tx.backupDir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(backupPath), os.ModePerm); err != nil {
+		return false, err
+	}
+
+	if err := moveFile(fpath, backupPath); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// keep backs up the "name" destination file, if exists, before it's written by the caller.
+func (tx *transaction) keep(name string) error {
+	backedUp, err := tx.backup(name)
+	if err != nil {
+		return err
+	}
+
+	tx.journal = append(tx.journal, &transactionEntry{name: name, backedUp: backedUp})
+	return nil
+}
+
+// move moves the staged "names", relative to the "stagingDir", into the destination directory.
+func (tx *transaction) move(stagingDir string, names []string) error {
+	files := make([]*installFile, 0, len(names))
+	for _, name := range names {
+		files = append(files, &installFile{staged: name, name: name})
+	}
+
+	return tx.install(stagingDir, files)
+}
+
+// rename renames the existing "name" destination file to "newName", it is kept after the installation.
+func (tx *transaction) rename(name, newName string) error {
+	if err := os.Rename(tx.path(name), tx.path(newName)); err != nil {
+		return err
+	}
+
+	tx.journal = append(tx.journal, &transactionEntry{name: newName, renamedFrom: name})
+	return nil
+}
+
+// install moves the staged "files", relative to the "stagingDir", into the destination directory
+// based on their conflict strategy.
+func (tx *transaction) install(stagingDir string, files []*installFile) error {
+	for _, f := range files {
+		if f.conflict == ConflictSkip {
+			continue
+		}
+
+		if f.backup != "" {
+			if err := tx.rename(f.name, f.backup); err != nil {
+				return err
+			}
+		}
+
+		stagedPath := filepath.Join(stagingDir, filepath.FromSlash(f.staged))
+		info, err := os.Lstat(stagedPath)
+		if err != nil {
+			return err
+		}
+
+		fpath := tx.path(f.name)
+
+		if info.IsDir() {
+			if destInfo, err := os.Lstat(fpath); err == nil && destInfo.IsDir() {
+				// Merge with the existing directory, its files are moved one by one.
+				continue
+			}
+
+			backedUp, err := tx.backup(f.name)
+			if err != nil {
+				return err
+			}
+
+			if err = os.Mkdir(fpath, info.Mode().Perm()); err != nil {
+				return err
+			}
+
+			tx.journal = append(tx.journal, &transactionEntry{name: f.name, backedUp: backedUp})
+			continue
+		}
+
+		backedUp, err := tx.backup(f.name)
+		if err != nil {
+			return err
+		}
+
+		if err = os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+			return err
+		}
+
+		if err = moveFile(stagedPath, fpath); err != nil {
+			return err
+		}
+
+		tx.journal = append(tx.journal, &transactionEntry{name: f.name, backedUp: backedUp})
+	}
+
+	return nil
+}
+
+// rollback removes the moved files and restores the backed up ones, in reverse order.
+func (tx *transaction) rollback() {
+	for i := len(tx.journal) - 1; i >= 0; i-- {
+		entry := tx.journal[i]
+		fpath := tx.path(entry.name)
+
+		if entry.renamedFrom != "" {
+			if err := os.Rename(fpath, tx.path(entry.renamedFrom)); err != nil {
+				golog.Errorf("rollback: rename: %s: %v", entry.renamedFrom, err)
+			}
+			continue
+		}
+
+		if err := os.RemoveAll(fpath); err != nil {
+			golog.Errorf("rollback: remove: %s: %v", entry.name, err)
+		}
+
+		if entry.backedUp {
+			backupPath := filepath.Join(tx.backupDir, filepath.FromSlash(entry.name))
+			if err := moveFile(backupPath, fpath); err != nil {
+				golog.Errorf("rollback: restore: %s: %v", entry.name, err)
+			}
+		}
+	}
+	tx.journal = nil
+
+	if tx.createdDest {
+		os.RemoveAll(tx.dest)
+	}
+
+	tx.close()
+}
+
+// close removes the backup directory.
+func (tx *transaction) close() {
+	if tx.backupDir != "" && strings.Contains(filepath.Base(tx.backupDir), ".iris-backup-") {
+		removeTempDir(tx.dest, tx.backupDir)
+	}
+}
+
+// tempDir creates a new temporary directory for the staged and the backed up files of the "dest" directory.
+// It is created under the destination's `StateDir`, which is never installed, so the parent directory
+// of the destination does not have to be writable and the files are moved with a rename.
+// It falls back to the `os.TempDir` if the destination is not writable, see `moveFile`.
+func tempDir(dest, pattern string) (string, error) {
+	dir := filepath.Join(dest, StateDir, "tmp")
+	if err := os.MkdirAll(dir, os.ModePerm); err == nil {
+		if tmp, err := ioutil.TempDir(dir, pattern); err == nil {
+			return tmp, nil
+		}
+	}
+
+	return ioutil.TempDir("", pattern)
+}
+
+// removeTempDir removes a "tmp" directory of `tempDir` and the destination's `StateDir` if it is left empty.
+func removeTempDir(dest, tmp string) {
+	os.RemoveAll(tmp)
+
+	// Not removed if they are not empty, e.g. the project's state.
+	os.Remove(filepath.Join(dest, StateDir, "tmp"))
+	os.Remove(filepath.Join(dest, StateDir))
+}
+
+// moveFile renames the "src" file or directory to "dst".
+// It copies and removes it when they are on different devices, e.g. a `tempDir` of the `os.TempDir`.
+func moveFile(src, dst string) error {
+	err := os.Rename(src, dst)
+	if _, ok := err.(*os.LinkError); !ok {
+		return err
+	}
+
+	// Any other failures, e.g. a missing "src" or an existing "dst", are not copied.
+	if _, srcErr := os.Lstat(src); srcErr != nil {
+		return err
+	}
+
+	if _, dstErr := os.Lstat(dst); !os.IsNotExist(dstErr) {
+		return err
+	}
+
+	if err = copyFile(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+
+	return os.RemoveAll(src)
+}
+
+// copyFile copies the "src" file, directory or symbolic link to "dst", with their modes.
+func copyFile(src, dst string) error {
+	return filepath.Walk(src, func(fpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, fpath)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.Mkdir(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(fpath)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			b, err := ioutil.ReadFile(fpath)
+			if err != nil {
+				return err
+			}
+			return ioutil.WriteFile(target, b, info.Mode().Perm())
+		default:
+			return fmt.Errorf("copy: %s: unsupported file mode: %s", fpath, info.Mode())
+		}
+	})
+}
@@ -0,0 +1,160 @@
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestTransactionRollback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		dest       = filepath.Join(dir, "app")
+		stagingDir = filepath.Join(dir, "staging")
+	)
+
+	writeTestFile(t, filepath.Join(dest, "main.go"), "user")
+	writeTestFile(t, filepath.Join(dest, "notes.txt"), "user")
+	writeTestFile(t, filepath.Join(stagingDir, "main.go"), "template")
+	writeTestFile(t, filepath.Join(stagingDir, "routes", "routes.go"), "template")
+
+	tx, err := newTransaction(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tx.move(stagingDir, []string{"main.go", "routes", "routes/routes.go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "template", readTestFile(t, filepath.Join(dest, "main.go")); expected != got {
+		t.Fatalf("expected main.go to be overwritten with: %q but got: %q", expected, got)
+	}
+
+	tx.rollback()
+
+	if expected, got := "user", readTestFile(t, filepath.Join(dest, "main.go")); expected != got {
+		t.Fatalf("expected main.go to be restored to: %q but got: %q", expected, got)
+	}
+
+	if expected, got := "user", readTestFile(t, filepath.Join(dest, "notes.txt")); expected != got {
+		t.Fatalf("expected notes.txt to be kept as: %q but got: %q", expected, got)
+	}
+
+	if _, err = os.Stat(filepath.Join(dest, "routes")); !os.IsNotExist(err) {
+		t.Fatalf("expected routes directory to be removed")
+	}
+
+	if _, err = os.Stat(tx.backupDir); !os.IsNotExist(err) {
+		t.Fatalf("expected backup directory to be removed")
+	}
+}
+
+func TestInstallTempDirs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	archiveFile := filepath.Join(dir, "basic.zip")
+	err = ioutil.WriteFile(archiveFile, newTestZip(t,
+		testZipEntry{Name: "app-main/main.go", Contents: "package main\n", Mode: 0644},
+	), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A user-owned destination inside a read-only directory, e.g. /srv/app.
+	parentDir := filepath.Join(dir, "srv")
+	dest := filepath.Join(parentDir, "app")
+	writeTestFile(t, filepath.Join(dest, "notes.txt"), "user")
+	if err = os.Chmod(parentDir, 0555); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(parentDir, 0755)
+
+	p := &Project{Repo: "file://" + filepath.ToSlash(archiveFile), Dest: dest, NoVerify: true}
+	if err = p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "package main\n", readTestFile(t, filepath.Join(dest, "main.go")); expected != got {
+		t.Fatalf("expected main.go contents: %q but got: %q", expected, got)
+	}
+
+	entries, err := os.ReadDir(parentDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 || entries[0].Name() != "app" {
+		t.Fatalf("expected no temporary directories next to the destination but got: %v", entries)
+	}
+
+	if _, err = os.Stat(filepath.Join(dest, StateDir, "tmp")); !os.IsNotExist(err) {
+		t.Fatalf("expected the temporary directory to be removed")
+	}
+}
+
+func TestCopyFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require privileges on windows")
+	}
+
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+	writeTestFile(t, filepath.Join(src, "main.go"), "package main\n")
+	writeTestFile(t, filepath.Join(src, "routes", "routes.go"), "package routes\n")
+	if err = os.Symlink("main.go", filepath.Join(src, "link.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(dir, "dst")
+	if err = copyFile(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "package routes\n", readTestFile(t, filepath.Join(dst, "routes", "routes.go")); expected != got {
+		t.Fatalf("expected routes.go contents: %q but got: %q", expected, got)
+	}
+
+	if target, err := os.Readlink(filepath.Join(dst, "link.go")); err != nil || target != "main.go" {
+		t.Fatalf("expected the symbolic link to be copied but got: %q (%v)", target, err)
+	}
+}
+
+func writeTestFile(t *testing.T, name, contents string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(name), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(name, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, name string) string {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(b)
+}
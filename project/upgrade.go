@@ -0,0 +1,392 @@
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// UpgradeResult describes the changes made by `Project.Upgrade`.
+type UpgradeResult struct {
+	Added     []string // new template files.
+	Updated   []string // files which were not modified locally.
+	Merged    []string // files which both the template and the project have changed.
+	Conflicts []string // files with conflicting changes, written with conflict markers.
+	Removed   []string // files removed from the template and not modified locally.
+	Kept      []string // files removed from the template but kept because they are modified locally.
+	// Existing are the template files which exist locally but are not project files, e.g. skipped on install,
+	// they are not merged, their conflict strategy is used instead, see `Project.OnConflict`.
+	Existing []string
+}
+
+// Upgrade upgrades the installed project to the "version" of its template.
+// The old (installed) and the new template versions are downloaded and
+// the template's changes are applied to the project's files as a three-way merge,
+// so the local modifications are preserved. Conflicting changes are written
+// with the standard conflict markers. Only the project's `Files` are merged,
+// any other existing files are resolved by their conflict strategy and they are never added to the `Files`.
+// The installed version is the recorded `Commit`, the "from" version overrides it,
+// e.g. for projects which were installed from a source without commits.
+// On success, the project file's Version, Commit, Answers and Files are updated.
+func (p *Project) Upgrade(version, from string) (result *UpgradeResult, err error) {
+	base := p.Commit
+	if from != "" {
+		base = from
+	}
+
+	if base == "" {
+		return nil, fmt.Errorf("project <%s>: no base version recorded, reinstall or pass --from", p.Name)
+	}
+
+	tmpDir, err := tempDir(p.Dest, ".iris-upgrade-")
+	if err != nil {
+		return nil, err
+	}
+	defer removeTempDir(p.Dest, tmpDir)
+
+	var (
+		baseDir = filepath.Join(tmpDir, "base")
+		newDir  = filepath.Join(tmpDir, "new")
+		outDir  = filepath.Join(tmpDir, "out")
+	)
+
+	if _, err = p.fetchVersion(base, baseDir, nil); err != nil {
+		return nil, fmt.Errorf("base version <%s>: %v", base, err)
+	}
+
+	next, err := p.fetchVersion(version, newDir, p.Prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	result = new(UpgradeResult)
+	var (
+		files   []string       // the new project files.
+		changed []*installFile // the files of the "outDir" to be moved.
+		removed []string
+		dirs    []string // directories removed from the template.
+		taken   = map[string]struct{}{}
+	)
+
+	newFiles := make(map[string]struct{}, len(next.Files))
+	for _, name := range next.Files {
+		newFiles[name] = struct{}{}
+	}
+
+	owned := make(map[string]struct{}, len(p.Files))
+	for _, name := range p.Files {
+		owned[name] = struct{}{}
+	}
+
+	for _, name := range next.Files {
+		newPath := filepath.Join(newDir, filepath.FromSlash(name))
+		newInfo, err := os.Lstat(newPath)
+		if err != nil {
+			return nil, err
+		}
+
+		localPath := filepath.Join(p.Dest, filepath.FromSlash(name))
+		localInfo, localErr := os.Lstat(localPath)
+		localExists := localErr == nil
+
+		if _, ok := owned[name]; localExists && !ok {
+			// Not installed by the template, e.g. skipped on conflict.
+			if newInfo.IsDir() || sameFile(newPath, localPath) {
+				continue
+			}
+
+			f := &installFile{staged: name, name: name}
+			if f.conflict, err = p.conflictStrategy(name); err != nil {
+				return nil, err
+			}
+
+			switch f.conflict {
+			case ConflictSkip:
+				result.Existing = append(result.Existing, name)
+				continue
+			case ConflictBackup:
+				f.backup = p.freeName(name+BackupExt, taken)
+			case ConflictNew:
+				f.name = p.freeName(name+NewExt, taken)
+			}
+
+			outPath := filepath.Join(outDir, filepath.FromSlash(name))
+			if err = os.MkdirAll(filepath.Dir(outPath), os.ModePerm); err != nil {
+				return nil, err
+			}
+
+			if err = os.Rename(newPath, outPath); err != nil {
+				return nil, err
+			}
+
+			result.Existing = append(result.Existing, name)
+			changed = append(changed, f)
+			continue
+		}
+
+		if newInfo.IsDir() {
+			files = append(files, name)
+			if !localExists {
+				if err = os.MkdirAll(filepath.Join(outDir, filepath.FromSlash(name)), newInfo.Mode().Perm()); err != nil {
+					return nil, err
+				}
+				changed = append(changed, &installFile{staged: name, name: name})
+			}
+			continue
+		}
+
+		if !newInfo.Mode().IsRegular() {
+			// Links are installed only when missing.
+			files = append(files, name)
+			if !localExists {
+				if err = copyLink(newPath, filepath.Join(outDir, filepath.FromSlash(name))); err != nil {
+					return nil, err
+				}
+				changed = append(changed, &installFile{staged: name, name: name})
+				result.Added = append(result.Added, name)
+			}
+			continue
+		}
+
+		newContents, err := ioutil.ReadFile(newPath)
+		if err != nil {
+			return nil, err
+		}
+
+		baseContents, baseErr := ioutil.ReadFile(filepath.Join(baseDir, filepath.FromSlash(name)))
+		baseExists := baseErr == nil
+
+		var (
+			contents []byte
+			mode     = newInfo.Mode().Perm()
+		)
+
+		switch {
+		case !localExists && !baseExists:
+			contents = newContents
+			result.Added = append(result.Added, name)
+		case !localExists:
+			if bytes.Equal(baseContents, newContents) {
+				continue // removed locally and not changed by the template.
+			}
+			contents = newContents
+			result.Conflicts = append(result.Conflicts, name) // removed locally but changed by the template.
+		case !localInfo.Mode().IsRegular():
+			files = append(files, name)
+			result.Conflicts = append(result.Conflicts, name)
+			continue
+		default:
+			localContents, err := ioutil.ReadFile(localPath)
+			if err != nil {
+				return nil, err
+			}
+
+			if bytes.Equal(localContents, newContents) || baseExists && bytes.Equal(baseContents, newContents) {
+				files = append(files, name) // up to date or changed locally only.
+				continue
+			}
+
+			if isBinary(localContents) || isBinary(newContents) || isBinary(baseContents) {
+				if !baseExists || !bytes.Equal(localContents, baseContents) {
+					files = append(files, name)
+					result.Conflicts = append(result.Conflicts, name) // keep the local binary file.
+					continue
+				}
+			}
+
+			if baseExists && bytes.Equal(localContents, baseContents) {
+				contents = newContents
+				result.Updated = append(result.Updated, name)
+				break
+			}
+
+			merged, conflicts := merge3(baseContents, localContents, newContents, "local", next.Version)
+			contents, mode = merged, localInfo.Mode().Perm()
+			if conflicts > 0 {
+				result.Conflicts = append(result.Conflicts, name)
+			} else {
+				result.Merged = append(result.Merged, name)
+			}
+		}
+
+		outPath := filepath.Join(outDir, filepath.FromSlash(name))
+		if err = os.MkdirAll(filepath.Dir(outPath), os.ModePerm); err != nil {
+			return nil, err
+		}
+
+		if err = ioutil.WriteFile(outPath, contents, mode); err != nil {
+			return nil, err
+		}
+
+		files = append(files, name)
+		changed = append(changed, &installFile{staged: name, name: name})
+	}
+
+	// Files removed from the template.
+	for _, name := range p.Files {
+		if _, ok := newFiles[name]; ok {
+			continue
+		}
+
+		localPath := filepath.Join(p.Dest, filepath.FromSlash(name))
+		localInfo, err := os.Lstat(localPath)
+		if err != nil {
+			continue // already removed.
+		}
+
+		if localInfo.IsDir() {
+			dirs = append(dirs, name)
+			continue
+		}
+
+		basePath := filepath.Join(baseDir, filepath.FromSlash(name))
+		if sameFile(basePath, localPath) {
+			removed = append(removed, name)
+			result.Removed = append(result.Removed, name)
+			continue
+		}
+
+		files = append(files, name)
+		result.Kept = append(result.Kept, name)
+	}
+
+	tx, err := newTransaction(p.Dest)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.rollback()
+			return
+		}
+
+		tx.close()
+	}()
+
+	for _, name := range removed {
+		if err = tx.keep(name); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.install(outDir, changed); err != nil {
+		return nil, err
+	}
+
+	// Remove the empty directories of the old template, deepest first.
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+	for _, name := range dirs {
+		if os.Remove(filepath.Join(p.Dest, filepath.FromSlash(name))) != nil {
+			files = append(files, name) // not empty.
+		}
+	}
+
+	for _, name := range projectFiles {
+		if err = tx.keep(name); err != nil {
+			return nil, err
+		}
+	}
+
+	p.Version = next.Version
+	p.Commit = next.Commit
+	p.SHA256 = next.SHA256
+	p.Answers = next.Answers
+	p.Files = files
+
+	if err = p.SaveToDisk(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// fetchVersion downloads and stages the project's template at "version" to the "dir" directory,
+// with the same module path, replacements and template answers.
+func (p *Project) fetchVersion(version, dir string, prompt func(*Template, map[string]interface{}) (map[string]interface{}, error)) (*Project, error) {
+	if version == "" {
+		return nil, fmt.Errorf("project <%s>: version is missing", p.Name)
+	}
+
+	t := &Project{
+		Name:         p.Name,
+		Repo:         p.Repo,
+		Version:      version,
+		Module:       p.Module,
+		Replacements: p.Replacements,
+		Answers:      p.Answers,
+		Cache:        p.Cache,
+		Offline:      p.Offline,
+		MaxSize:      p.MaxSize,
+		MaxFiles:     p.MaxFiles,
+		RewriteFiles: p.RewriteFiles,
+		Reader:       p.Reader,
+		Prompt:       prompt,
+	}
+
+	b, format, err := t.download()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = t.stage(b, format, dir); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func isBinary(contents []byte) bool {
+	return bytes.IndexByte(contents, 0) != -1
+}
+
+func sameFile(a, b string) bool {
+	ac, err := ioutil.ReadFile(a)
+	if err != nil {
+		return false
+	}
+
+	bc, err := ioutil.ReadFile(b)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(ac, bc)
+}
+
+func copyLink(src, dst string) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.Symlink(target, dst)
+}
+
+// String returns a summary of the upgrade.
+func (r *UpgradeResult) String() string {
+	var b strings.Builder
+	write := func(title string, names []string) {
+		for _, name := range names {
+			b.WriteString(title + " " + name + "\n")
+		}
+	}
+
+	write("added   ", r.Added)
+	write("updated ", r.Updated)
+	write("merged  ", r.Merged)
+	write("conflict", r.Conflicts)
+	write("removed ", r.Removed)
+	write("kept    ", r.Kept)
+	write("existing", r.Existing)
+
+	return b.String()
+}
@@ -0,0 +1,208 @@
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	base := "a\nb\nc\nd\ne\n"
+
+	tests := []struct {
+		local, other string
+		expected     string
+		conflicts    int
+	}{
+		{"a\nB\nc\nd\ne\n", "a\nb\nc\nd\nE\n", "a\nB\nc\nd\nE\n", 0},
+		{"a\nb\nc\nd\ne\n", "a\nb\nx\nc\nd\ne\n", "a\nb\nx\nc\nd\ne\n", 0},
+		{"a\nB\nc\nd\ne\n", "a\nB\nc\nd\ne\n", "a\nB\nc\nd\ne\n", 0},
+		{"a\nlocal\nc\nd\ne\n", "a\nother\nc\nd\ne\n", "a\n<<<<<<< local\nlocal\n=======\nother\n>>>>>>> v2\nc\nd\ne\n", 1},
+	}
+
+	for i, tt := range tests {
+		got, conflicts := merge3([]byte(base), []byte(tt.local), []byte(tt.other), "local", "v2")
+		if string(got) != tt.expected || conflicts != tt.conflicts {
+			t.Fatalf("[%d] expected (%d conflicts):\n%s\nbut got (%d conflicts):\n%s", i, tt.conflicts, tt.expected, conflicts, got)
+		}
+	}
+}
+
+type testVersionsSource map[string][]byte
+
+func (s testVersionsSource) Archive(repo, version string) (*Archive, error) {
+	b, ok := s[version]
+	if !ok {
+		return nil, fmt.Errorf("version <%s> not found", version)
+	}
+
+	// The versions are their own commits.
+	return &Archive{Body: ioutil.NopCloser(bytes.NewReader(b)), Format: ArchiveZip, Commit: version}, nil
+}
+
+func (s testVersionsSource) Versions(repo string) []string {
+	return nil
+}
+
+func TestUpgrade(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	RegisterSource("upgradetest", testVersionsSource{
+		"v1": newTestZip(t,
+			testZipEntry{Name: "app-main/main.go", Contents: "package main\n\n// v1\nfunc main() {\n}\n", Mode: 0644},
+			testZipEntry{Name: "app-main/README.md", Contents: "# app\n", Mode: 0644},
+			testZipEntry{Name: "app-main/old.txt", Contents: "old\n", Mode: 0644},
+			testZipEntry{Name: "app-main/config.yml", Contents: "port: 8080\n", Mode: 0644},
+		),
+		"v2": newTestZip(t,
+			testZipEntry{Name: "app-main/main.go", Contents: "package main\n\n// v2\nfunc main() {\n}\n", Mode: 0644},
+			testZipEntry{Name: "app-main/README.md", Contents: "# app\n\nv2 docs\n", Mode: 0644},
+			testZipEntry{Name: "app-main/config.yml", Contents: "port: 9090\n", Mode: 0644},
+			testZipEntry{Name: "app-main/new.txt", Contents: "new\n", Mode: 0644},
+		),
+	})
+	defer delete(sources, "upgradetest")
+
+	p := &Project{Name: "app", Repo: "upgradetest:app", Version: "v1", Dest: filepath.Join(dir, "app")}
+	if err = p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	writeTestFile(t, filepath.Join(p.Dest, "main.go"), "package main\n\n// v1\nfunc main() {\n\tprintln(\"local\")\n}\n")
+	writeTestFile(t, filepath.Join(p.Dest, "config.yml"), "port: 3000\n")
+
+	p, err = LoadFromDisk(p.Dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The base version is never guessed.
+	commit := p.Commit
+	p.Commit = ""
+	if _, err = p.Upgrade("v2", ""); err == nil || !strings.Contains(err.Error(), "no base version recorded") {
+		t.Fatalf("expected an error without a base version but got: %v", err)
+	}
+	p.Commit = commit
+
+	result, err := p.Upgrade("v2", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "package main\n\n// v2\nfunc main() {\n\tprintln(\"local\")\n}\n", readTestFile(t, filepath.Join(p.Dest, "main.go")); expected != got {
+		t.Fatalf("expected merged main.go:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	if expected, got := "# app\n\nv2 docs\n", readTestFile(t, filepath.Join(p.Dest, "README.md")); expected != got {
+		t.Fatalf("expected updated README.md: %q but got: %q", expected, got)
+	}
+
+	if expected, got := "<<<<<<< local\nport: 3000\n=======\nport: 9090\n>>>>>>> v2\n", readTestFile(t, filepath.Join(p.Dest, "config.yml")); expected != got {
+		t.Fatalf("expected conflict in config.yml:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	if _, err = os.Stat(filepath.Join(p.Dest, "old.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected old.txt to be removed")
+	}
+
+	if expected, got := "new\n", readTestFile(t, filepath.Join(p.Dest, "new.txt")); expected != got {
+		t.Fatalf("expected added new.txt: %q but got: %q", expected, got)
+	}
+
+	if len(result.Merged) != 1 || len(result.Updated) != 1 || len(result.Conflicts) != 1 || len(result.Added) != 1 || len(result.Removed) != 1 {
+		t.Fatalf("unexpected result:\n%s", result)
+	}
+
+	p, err = LoadFromDisk(p.Dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, got := "v2", p.Version; expected != got {
+		t.Fatalf("expected project version: %q but got: %q", expected, got)
+	}
+}
+
+func TestUpgradeExistingFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iris-cli-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	RegisterSource("upgradeexisting", testVersionsSource{
+		"v1": newTestZip(t,
+			testZipEntry{Name: "app-main/README.md", Contents: "# tmpl v1\n", Mode: 0644},
+			testZipEntry{Name: "app-main/Makefile", Contents: "build: v1\n", Mode: 0644},
+		),
+		"v2": newTestZip(t,
+			testZipEntry{Name: "app-main/README.md", Contents: "# tmpl v2\n", Mode: 0644},
+			testZipEntry{Name: "app-main/Makefile", Contents: "build: v2\n", Mode: 0644},
+		),
+	})
+	defer delete(sources, "upgradeexisting")
+
+	dest := filepath.Join(dir, "app")
+	writeTestFile(t, filepath.Join(dest, "README.md"), "# my own readme\n")
+	writeTestFile(t, filepath.Join(dest, "Makefile"), "build: mine\n")
+
+	p := &Project{Name: "app", Repo: "upgradeexisting:app", Version: "v1", Dest: dest, OnConflict: ConflictSkip}
+	if err = p.Install(); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err = LoadFromDisk(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.OnConflict = ConflictSkip
+	p.Conflicts = map[string]string{"Makefile": ConflictNew}
+
+	p.Commit = "" // e.g. installed from a source without commits.
+	result, err := p.Upgrade("v2", "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]string{
+		"README.md":         "# my own readme\n",
+		"Makefile":          "build: mine\n",
+		"Makefile" + NewExt: "build: v2\n",
+	} {
+		if got := readTestFile(t, filepath.Join(dest, name)); expected != got {
+			t.Fatalf("%s: expected contents: %q but got: %q", name, expected, got)
+		}
+	}
+
+	if len(result.Existing) != 2 || len(result.Conflicts) > 0 || len(result.Merged) > 0 {
+		t.Fatalf("unexpected result:\n%s", result)
+	}
+
+	if p, err = LoadFromDisk(dest); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range p.Files {
+		if name == "README.md" || name == "Makefile" {
+			t.Fatalf("expected the existing %s to not be a project file: %v", name, p.Files)
+		}
+	}
+
+	if err = p.Unistall(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"README.md", "Makefile"} {
+		if _, err = os.Stat(filepath.Join(dest, name)); err != nil {
+			t.Fatalf("expected %s to be kept after unistall: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package project
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+
+	"github.com/kataras/iris-cli/utils"
+)
+
+// UserConfigEnv is the environment variable which overrides the default user configuration file.
+const UserConfigEnv = "IRIS_CLI_CONFIG"
+
+// DefaultUserConfigFile returns the IRIS_CLI_CONFIG environment variable
+// or the "iris-cli/config.yml" file under the user's configuration directory.
+func DefaultUserConfigFile() string {
+	if file := os.Getenv(UserConfigEnv); file != "" {
+		return file
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "iris-cli", "config.yml")
+}
+
+// UserConfig is the per-user configuration of the iris-cli,
+// it is not part of any project.
+//
+// Example:
+//
+//	TrustedKeys:
+//	  - 3Jx2...base64 ed25519 public key...=
+//	Registries:
+//	  - Endpoint: https://registry.acme.com/registry.yml
+//	    Namespace: acme
+//	  - Endpoint: https://raw.githubusercontent.com/kataras/iris-cli/main/registry.yml
+type UserConfig struct {
+	// TrustedKeys are the base64 encoded ed25519 public keys
+	// which registry signatures are verified against.
+	TrustedKeys []string `json:"trusted_keys,omitempty" yaml:"TrustedKeys,omitempty" toml:"TrustedKeys"`
+	// Registries are the registries to be merged instead of the default one.
+	Registries []*RegistrySource `json:"registries,omitempty" yaml:"Registries,omitempty" toml:"Registries"`
+
+	file string
+}
+
+// LoadUserConfig reads the user configuration "file".
+// If "file" is empty then the `DefaultUserConfigFile` is used instead.
+// A missing file results to an empty configuration.
+func LoadUserConfig(file string) (*UserConfig, error) {
+	if file == "" {
+		file = DefaultUserConfigFile()
+	}
+
+	c := &UserConfig{file: file}
+	if !utils.Exists(file) {
+		return c, nil
+	}
+
+	if err := utils.Import(file, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Save writes the configuration to its file.
+func (c *UserConfig) Save() error {
+	return utils.Export(c.file, c)
+}
+
+// PublicKeys returns the decoded `TrustedKeys`.
+func (c *UserConfig) PublicKeys() ([]ed25519.PublicKey, error) {
+	keys := make([]ed25519.PublicKey, 0, len(c.TrustedKeys))
+	for _, s := range c.TrustedKeys {
+		key, err := ParsePublicKey(s)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
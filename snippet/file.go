@@ -18,7 +18,12 @@ import (
 const supportedType = "file" // ignore dirs.
 
 // ListFiles returns a github repository's files.
+// The "repo" can be a local directory too (file://), e.g. a mirror, its version is ignored.
 func ListFiles(repo, version string) ([]*File, error) {
+	if dir, ok := localRepo(repo); ok {
+		return listLocalFiles(dir)
+	}
+
 	rep, v := utils.SplitNameVersion(repo)
 	if rep != "" {
 		repo = rep
@@ -32,20 +37,19 @@ func ListFiles(repo, version string) ([]*File, error) {
 		version = "main"
 	}
 
-	var resp []*File
-
 	url := fmt.Sprintf("https://api.github.com/repos/%s/contents?ref=%s", repo, version)
 	b, err := utils.Download(url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var files []*File
+	var resp []*File
 
-	if err := json.Unmarshal(b, &files); err != nil {
+	if err := json.Unmarshal(b, &resp); err != nil {
 		return nil, err
 	}
 
+	files := make([]*File, 0, len(resp))
 	for _, f := range resp {
 		if f.Type != supportedType || f.DownloadURL == "" || f.DownloadURL == "null" {
 			continue
@@ -57,6 +61,68 @@ func ListFiles(repo, version string) ([]*File, error) {
 	return files, nil
 }
 
+// localRepo returns the directory of a local "repo", a file:// URL or an existing directory.
+func localRepo(repo string) (string, bool) {
+	if strings.HasPrefix(repo, "file://") {
+		return filepath.FromSlash(strings.TrimPrefix(repo, "file://")), true
+	}
+
+	if utils.IsDir(repo) {
+		return repo, true
+	}
+
+	return "", false
+}
+
+func listLocalFiles(dir string) ([]*File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*File, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		files = append(files, &File{
+			Repo:        dir,
+			Name:        entry.Name(),
+			Type:        supportedType,
+			DownloadURL: "file://" + filepath.ToSlash(filepath.Join(dir, entry.Name())),
+		})
+	}
+
+	return files, nil
+}
+
+// Download saves the files of a github repository's "version" to the "dir" directory, as they are,
+// so the directory can be used as a local repository, e.g. "iris-cli add --repo=./mirror/snippets".
+func Download(repo, version, dir string) ([]*File, error) {
+	files, err := ListFiles(repo, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	for _, f := range files {
+		b, err := f.download()
+		if err != nil {
+			return nil, fmt.Errorf("snippet <%s>: %v", f.Name, err)
+		}
+
+		if err = os.WriteFile(filepath.Join(dir, filepath.Base(f.Name)), b, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
 // File represents a github file to be locally saved.
 // See `ListFiles` package-level function too.
 type File struct {
@@ -104,10 +170,10 @@ func IsMissingKeys(err error) ([]string, bool) {
 	return nil, false
 }
 
-// Install downloads and performs necessary tasks to save a remote file.
-func (f *File) Install() error {
-	if f.Version == "" || f.Version == "latest" {
-		f.Version = "main"
+// download returns the remote file's contents, or reads them from a local repository.
+func (f *File) download() ([]byte, error) {
+	if dir, ok := localRepo(f.Repo); ok {
+		return os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Name)))
 	}
 
 	if f.Repo == "" && f.DownloadURL != "" {
@@ -120,7 +186,16 @@ func (f *File) Install() error {
 		f.DownloadURL = fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s", f.Repo, f.Version, f.Name)
 	}
 
-	b, err := utils.Download(f.DownloadURL, nil)
+	return utils.Download(f.DownloadURL, nil)
+}
+
+// Install downloads and performs necessary tasks to save a remote file.
+func (f *File) Install() error {
+	if f.Version == "" || f.Version == "latest" {
+		f.Version = "main"
+	}
+
+	b, err := f.download()
 	if err != nil {
 		return err
 	}
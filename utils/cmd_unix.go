@@ -36,13 +36,16 @@ func KillCommand(cmd *exec.Cmd) error {
 
 func FormatExecutable(bin string) string { return bin }
 
-func StartExecutable(dir, bin string, stdout, stderr io.Writer) (*exec.Cmd, error) {
+// StartExecutable starts the "bin" executable of the "dir" directory.
+// The "env" is the environment of the process, if nil then it inherits the current one.
+func StartExecutable(dir, bin string, env []string, stdout, stderr io.Writer) (*exec.Cmd, error) {
 	if IsInsideDocker() {
 		// If run through docker, this part is required,
 		// otherwise we should NOT try this because it always gives error:
 		cmd := Command("/bin/sh", "-c", bin)
 		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true} // set parent group id in order to be kill-able.
 		cmd.Dir = dir
+		cmd.Env = env
 		cmd.Stdout = stdout
 		cmd.Stderr = stderr
 		_, err := pty.Start(cmd)
@@ -58,6 +61,7 @@ func StartExecutable(dir, bin string, stdout, stderr io.Writer) (*exec.Cmd, erro
 	cmd := Command(path.Join(dir, bin))
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 	cmd.Dir = dir
+	cmd.Env = env
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 	if err := cmd.Start(); err != nil {
@@ -52,10 +52,13 @@ func FormatExecutable(bin string) string {
 	return bin
 }
 
-func StartExecutable(dir, bin string, stdout, stderr io.Writer) (*exec.Cmd, error) {
+// StartExecutable starts the "bin" executable of the "dir" directory.
+// The "env" is the environment of the process, if nil then it inherits the current one.
+func StartExecutable(dir, bin string, env []string, stdout, stderr io.Writer) (*exec.Cmd, error) {
 	cmd := Command("cmd", "/c", bin)
 	// cmd, cancelFunc := CommandWithCancel(bin) // here the cmd.Process.Pid will give the program's correct PID
 	cmd.Dir = dir
+	cmd.Env = env
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 	return cmd, cmd.Start()
@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"strings"
+)
+
+// GitConfig returns the value of a git configuration "key", e.g. "user.name",
+// or empty if git is not installed or the key is missing.
+func GitConfig(key string) string {
+	out, err := Command("git", "config", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"strings"
+)
+
+// GoVersion returns the version of the local Go toolchain, e.g. "go1.22.1",
+// or empty if Go is not installed.
+func GoVersion() string {
+	out, err := Command("go", "env", "GOVERSION").Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
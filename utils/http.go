@@ -29,6 +29,19 @@ func Download(url string, body io.Reader, options ...DownloadOption) ([]byte, er
 	return ioutil.ReadAll(r)
 }
 
+// DownloadWithHeader returns the body of "url" and the response's header,
+// e.g. to read the Content-Type of the resource.
+func DownloadWithHeader(url string, body io.Reader, options ...DownloadOption) ([]byte, http.Header, error) {
+	r, header, err := downloadReader(url, body, options...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+
+	b, err := ioutil.ReadAll(r)
+	return b, header, err
+}
+
 // DefaultClient is the default client all http requests are fired from.
 var DefaultClient = http.DefaultClient
 
@@ -67,42 +80,47 @@ func (t *transportWrapper) RoundTrip(req *http.Request) (*http.Response, error)
 
 // DownloadReader returns a response reader.
 func DownloadReader(url string, body io.Reader, options ...DownloadOption) (io.ReadCloser, error) {
+	r, _, err := downloadReader(url, body, options...)
+	return r, err
+}
+
+func downloadReader(url string, body io.Reader, options ...DownloadOption) (io.ReadCloser, http.Header, error) {
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	req.Header.Add("Accept-Encoding", "gzip")
 
 	for _, opt := range options {
 		if err = opt(req); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	resp, err := DefaultClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	// defer resp.Body.Close()
 	var reader io.ReadCloser = resp.Body
 
 	if code := resp.StatusCode; code < 200 || code >= 400 {
 		reader.Close()
-		return nil, fmt.Errorf("resource not available <%s>: %s", url, resp.Status)
+		return nil, nil, fmt.Errorf("resource not available <%s>: %s", url, resp.Status)
 	}
 
 	if strings.Contains(resp.Header.Get("Content-Encoding"), "gzip") {
 		gzipReader, err := gzip.NewReader(reader)
 		if err != nil {
 			reader.Close()
-			return nil, err
+			return nil, nil, err
 		}
 
 		// defer gzipReader.Close()
 		reader = multiCloser{Reader: gzipReader, closers: []io.ReadCloser{gzipReader, reader}}
 	}
 
-	return reader, nil
+	return reader, resp.Header, nil
 }
 
 // ListReleases lists all releases of a github "repo".
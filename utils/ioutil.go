@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,6 +15,7 @@ import (
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/pelletier/go-toml/v2"
 	"gopkg.in/yaml.v3"
 )
 
@@ -376,36 +378,54 @@ func NewWatcher() (*Watcher, error) {
 
 // Export exports "v" to "destFile" system file.
 // It creates it if it does not exist and overrides it if contains data.
+// The format is based on the file extension: .json, .yml, .yaml or .toml.
 func Export(destFile string, v interface{}) error {
+	b, err := Marshal(destFile, v)
+	if err != nil {
+		return err
+	}
+
 	destFile = filepath.ToSlash(destFile)
 	if dir := path.Dir(destFile); len(dir) > 1 {
 		os.MkdirAll(dir, 0666)
 	}
 
-	f, err := os.OpenFile(destFile, os.O_RDWR|os.O_TRUNC|os.O_CREATE, 0666)
-	if err != nil {
-		return err
-	}
+	return os.WriteFile(destFile, b, 0666)
+}
 
-	var encoder interface {
-		Encode(interface{}) error
-	}
+// Marshal returns the contents of "v" as they would be exported to the "destFile", see `Export`.
+// The format is based on the file extension: .json, .yml, .yaml or .toml.
+func Marshal(destFile string, v interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
 
-	switch ext := path.Ext(destFile); ext {
+	switch ext := path.Ext(filepath.ToSlash(destFile)); ext {
 	case ".json":
-		encoder = json.NewEncoder(f)
+		enc := json.NewEncoder(buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
 	case ".yml", ".yaml":
-		enc := yaml.NewEncoder(f)
-		defer enc.Close()
-		encoder = enc
+		enc := yaml.NewEncoder(buf)
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+	case ".toml":
+		if err := toml.NewEncoder(buf).Encode(v); err != nil {
+			return nil, err
+		}
 	default:
-		return fmt.Errorf("unexpected file extension: %s", ext)
+		return nil, fmt.Errorf("unexpected file extension: %s", ext)
 	}
 
-	return encoder.Encode(v)
+	return buf.Bytes(), nil
 }
 
 // Import decodes a file to "dest".
+// The format is based on the file extension: .json, .yml, .yaml or .toml.
 func Import(sourceFile string, dest interface{}) error {
 	f, err := os.Open(sourceFile)
 	if err != nil {
@@ -428,6 +448,8 @@ func Import(sourceFile string, dest interface{}) error {
 		decoder = json.NewDecoder(f)
 	case ".yml", ".yaml":
 		decoder = yaml.NewDecoder(f)
+	case ".toml":
+		decoder = toml.NewDecoder(f)
 	default:
 		return fmt.Errorf("unexpected file extension: %s", ext)
 	}
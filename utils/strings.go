@@ -5,14 +5,26 @@ import (
 )
 
 // SplitNameVersion accepts a string and returns its name and version.
+// The version is the part after the last "@", e.g. name@v1.0.0 or name@feature/x,
+// it is empty when the string has no version so the caller can pick its default one.
+// The "@" of a URL's user or of a scp-like git url is part of the name,
+// e.g. git@github.com:owner/repo.git@v1.0.0 and https://user@host/repo.git@v1.0.0.
 func SplitNameVersion(s string) (name string, version string) {
-	nameBranch := strings.Split(s, "@")
-	name = nameBranch[0]
-	if len(nameBranch) > 1 {
-		version = nameBranch[1]
-	} else {
-		version = "main"
+	start := 0
+	if i := strings.Index(s, "://"); i >= 0 {
+		// The version is part of the URL's path.
+		start = len(s)
+		if slash := strings.IndexByte(s[i+3:], '/'); slash >= 0 {
+			start = i + 3 + slash
+		}
+	} else if at, colon := strings.IndexByte(s, '@'), strings.IndexByte(s, ':'); at > 0 && colon > at {
+		// The version follows the path of a scp-like git url, git refs cannot contain a colon.
+		start = colon
 	}
 
-	return
+	if idx := strings.LastIndexByte(s, '@'); idx > start {
+		return s[:idx], s[idx+1:]
+	}
+
+	return s, ""
 }